@@ -0,0 +1,336 @@
+package candyjs
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var (
+	typeJSONUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	reAnonymousFunc = regexp.MustCompile(`^func\d+$`)
+)
+
+// SetStrictArguments sets whether the arguments of the Go functions called
+// from JS are checked, strict by default. When strict, calling a function with
+// missing arguments, or with an argument not convertible to the type of its
+// parameter, throws a TypeError like:
+//
+//	multiply: argument 1 expected int, got string
+//
+// An explicit null or undefined is the zero value of any type. The trailing
+// parameters of nilable types (pointers, interfaces, maps, slices, channels and
+// functions) are optional, a missing argument is nil.
+// When not strict the missing arguments are zero values. The extra arguments
+// are always ignored, as JS does.
+func (ctx *Context) SetStrictArguments(strict bool) {
+	ctx.looseArgs = !strict
+}
+
+// funcName returns the name of the function f, used by the errors of the
+// arguments.
+func funcName(f interface{}) string {
+	if fn, ok := f.(Func); ok {
+		f = fn.Fn
+	}
+
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return "function"
+	}
+
+	name := strings.TrimSuffix(fn.Name(), "-fm")
+	if strings.HasPrefix(name, "reflect.") {
+		return "function" // method value made by package reflect
+	}
+
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	if reAnonymousFunc.MatchString(name) {
+		return "function"
+	}
+
+	return name
+}
+
+// checkArgs checks the arguments in the stack against the parameters of the
+// function f, the positions in the errors start at 1.
+func (ctx *Context) checkArgs(name string, f interface{}) error {
+	def := reflect.TypeOf(f)
+	injected := injectedCount(def)
+	inCount := def.NumIn() - injected
+	argc := ctx.GetTop()
+
+	required := inCount
+	if def.IsVariadic() {
+		required--
+	}
+
+	for required > argc && isNilable(def.In(injected+required-1)) {
+		required--
+	}
+
+	switch {
+	case argc >= required:
+	case def.IsVariadic() || required < inCount:
+		return errorf(ErrorCodeTypeError, "%s: expected at least %d arguments, got %d", name, required, argc)
+	default:
+		return errorf(ErrorCodeTypeError, "%s: expected %d arguments, got %d", name, inCount, argc)
+	}
+
+	for index := 0; index < argc; index++ {
+		var t reflect.Type
+		switch {
+		case def.IsVariadic() && index >= inCount-1:
+			t = def.In(def.NumIn() - 1).Elem()
+		case index < inCount:
+			t = def.In(injected + index)
+		default:
+			return nil // extra args are ignored
+		}
+
+		if _, ok, err := ctx.getTimeValue(index, t); ok && err != nil {
+			return errorf(ErrorCodeInvalidTime, "%s: argument %d %s", name, index+1, err)
+		}
+
+		if !ctx.isConvertible(index, t) {
+			return errorf(ErrorCodeTypeError, "%s: argument %d expected %s, got %s",
+				name, index+1, t, ctx.typeName(index))
+		}
+	}
+
+	return nil
+}
+
+// isConvertible returns true if the value at the given index can be decoded
+// into a value of type t.
+func (ctx *Context) isConvertible(index int, t reflect.Type) bool {
+	if proxy, ok := ctx.getProxyValue(index, t); ok {
+		return proxy.Type().AssignableTo(t)
+	}
+
+	if ctx.IsNullOrUndefined(index) {
+		return true // zero value
+	}
+
+	if reflect.PtrTo(t).Implements(typeJSONUnmarshaler) {
+		return true
+	}
+
+	if ctx.IsString(index) && reflect.PtrTo(t).Implements(typeTextUnmarshaler) {
+		return true
+	}
+
+	if _, ok, err := ctx.getTimeValue(index, t); ok {
+		return err == nil
+	}
+
+	if ctx.isBytes(index) {
+		return isBytesType(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return ctx.IsBoolean(index)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return ctx.IsNumber(index)
+	case reflect.String:
+		return ctx.IsString(index)
+	case reflect.Slice, reflect.Array:
+		return ctx.IsArray(index)
+	case reflect.Map, reflect.Struct:
+		return ctx.IsObject(index) && !ctx.IsFunction(index) && !ctx.IsArray(index)
+	case reflect.Ptr:
+		return ctx.isConvertible(index, t.Elem())
+	case reflect.Func:
+		return ctx.IsFunction(index) || ctx.IsPointer(index)
+	case reflect.Interface:
+		return t.NumMethod() == 0 || ctx.isJSWriter(index, t) || (ctx.IsError(index) && typeJSError.Implements(t))
+	}
+
+	return false
+}
+
+var typeJSError = reflect.TypeOf(&JSError{})
+
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func, reflect.Interface, reflect.Chan:
+		return true
+	}
+
+	return false
+}
+
+// isBytes returns true if the value at the given index is a buffer, an
+// ArrayBuffer or a typed array.
+func (ctx *Context) isBytes(index int) bool {
+	if ctx.IsBuffer(index) {
+		return true
+	}
+
+	if !ctx.IsObject(index) || ctx.IsFunction(index) {
+		return false
+	}
+
+	ctx.GetPropString(index, "byteLength")
+	defer ctx.Pop()
+
+	return ctx.IsNumber(-1)
+}
+
+// typeName returns the type of the value at the given index used by the
+// errors of the arguments: the JS type or, for the proxies, the Go type.
+func (ctx *Context) typeName(index int) string {
+	if proxy := ctx.getProxy(index); proxy != nil {
+		return fmt.Sprintf("%T", proxy)
+	}
+
+	switch {
+	case ctx.IsUndefined(index):
+		return "undefined"
+	case ctx.IsNull(index):
+		return "null"
+	case ctx.IsBoolean(index):
+		return "boolean"
+	case ctx.IsNumber(index):
+		return "number"
+	case ctx.IsString(index):
+		return "string"
+	case ctx.IsFunction(index):
+		return "function"
+	case ctx.IsArray(index):
+		return "array"
+	case ctx.isBytes(index):
+		return "buffer"
+	case ctx.isDate(index):
+		return "Date"
+	case ctx.IsError(index):
+		return "Error"
+	}
+
+	return "object"
+}
+
+// Args gives access to the raw arguments of a Go function of the form:
+//
+//	func(args *candyjs.Args) (interface{}, error)
+//
+// These functions get no argument checked nor decoded, they inspect the JS
+// arguments themselves, the result is pushed as the result of any other Go
+// function. An Args is only valid during the call.
+type Args struct {
+	ctx  *Context
+	name string
+}
+
+var typeArgsFunc = reflect.TypeOf((func(*Args) (interface{}, error))(nil))
+
+// Len returns the number of arguments, as `arguments.length`.
+func (a *Args) Len() int {
+	return a.ctx.GetTop()
+}
+
+// Type returns the type of the i-th argument: "undefined", "null", "boolean",
+// "number", "string", "function", "array", "buffer", "Date", "Error" or
+// "object", or the Go type of the proxies like "*main.User". The missing
+// arguments are "undefined".
+func (a *Args) Type(i int) string {
+	if !a.has(i) {
+		return "undefined"
+	}
+
+	return a.ctx.typeName(i)
+}
+
+// IsUndefined returns true if the i-th argument is undefined or missing, the
+// null arguments are not undefined.
+func (a *Args) IsUndefined(i int) bool {
+	return !a.has(i) || a.ctx.IsUndefined(i)
+}
+
+// String returns the i-th argument converted to a string as JS does, the
+// missing arguments are "".
+func (a *Args) String(i int) string {
+	if !a.has(i) {
+		return ""
+	}
+
+	a.ctx.Dup(i)
+	defer a.ctx.Pop()
+
+	return a.ctx.SafeToString(-1)
+}
+
+// Int returns the i-th argument converted to an int as JS does, the missing
+// arguments are 0.
+func (a *Args) Int(i int) int {
+	if !a.has(i) {
+		return 0
+	}
+
+	a.ctx.Dup(i)
+	defer a.ctx.Pop()
+
+	return a.ctx.ToInt(-1)
+}
+
+// Decode decodes the i-th argument into the value pointed by v, following the
+// rules of the parameters of the Go functions. A TypeError is returned if the
+// argument is not convertible, see SetStrictArguments.
+func (a *Args) Decode(i int, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errorf(ErrorCodeTypeError, "%s: decode of argument %d into non-pointer %T", a.name, i+1, v)
+	}
+
+	t := rv.Elem().Type()
+	if !a.has(i) {
+		rv.Elem().Set(reflect.Zero(t))
+		return nil
+	}
+
+	if !a.ctx.isConvertible(i, t) {
+		return errorf(ErrorCodeTypeError, "%s: argument %d expected %s, got %s",
+			a.name, i+1, t, a.ctx.typeName(i))
+	}
+
+	if val := a.ctx.getValueFromContext(i, t); val.IsValid() {
+		rv.Elem().Set(val)
+	} else {
+		rv.Elem().Set(reflect.Zero(t))
+	}
+
+	return nil
+}
+
+// Function returns the i-th argument as a Go function calling it, nil if it
+// is not a function. The returned function can be kept once the call returns.
+func (a *Args) Function(i int) func(args ...interface{}) (interface{}, error) {
+	if !a.has(i) || !a.ctx.IsFunction(i) {
+		return nil
+	}
+
+	var fn func(args ...interface{}) (interface{}, error)
+	reflect.ValueOf(&fn).Elem().Set(a.ctx.getValueFromContext(i, reflect.TypeOf(fn)))
+	return fn
+}
+
+// This returns the JS receiver of the call, see This.
+func (a *Args) This() This {
+	return a.ctx.getThis().Interface()
+}
+
+func (a *Args) has(i int) bool {
+	return i >= 0 && i < a.Len()
+}
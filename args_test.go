@@ -0,0 +1,167 @@
+package candyjs
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestArgumentsType(c *C) {
+	s.ctx.PushGlobalGoFunction("golangMultiply", func(a, b int) int { return a * b })
+
+	c.Assert(s.ctx.PevalString(`
+		try {
+			golangMultiply("a", 2)
+		} catch (e) {
+			store([e instanceof TypeError, e.message, e.code])
+		}
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		true, "golangMultiply: argument 1 expected int, got string", ErrorCodeTypeError,
+	})
+}
+
+func (s *CandySuite) TestArgumentsArity(c *C) {
+	s.ctx.PushGlobalGoFunction("golangMultiply", func(a, b int) int { return a * b })
+
+	err := s.ctx.PevalString(`golangMultiply(2)`)
+	c.Assert(err, ErrorMatches, "golangMultiply: expected 2 arguments, got 1")
+	c.Assert(ErrorCode(err), Equals, ErrorCodeTypeError)
+
+	c.Assert(s.ctx.PevalString(`store(golangMultiply(2, 3, 4))`), IsNil)
+	c.Assert(s.stored, Equals, 6.0)
+}
+
+func (s *CandySuite) TestArgumentsVariadic(c *C) {
+	s.ctx.PushGlobalGoFunction("sum", func(s string, is ...int) {})
+
+	c.Assert(s.ctx.PevalString(`sum()`), ErrorMatches, "sum: expected at least 1 arguments, got 0")
+	c.Assert(s.ctx.PevalString(`sum("foo", 1, "2")`), ErrorMatches, "sum: argument 3 expected int, got string")
+	c.Assert(s.ctx.PevalString(`sum("foo", 1, 2)`), IsNil)
+}
+
+func (s *CandySuite) TestArgumentsOptional(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(i int, m map[string]interface{}, p *MyStruct) {
+		s.stored = []interface{}{i, m == nil, p == nil}
+	})
+
+	c.Assert(s.ctx.PevalString(`test(42)`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{42, true, true})
+
+	c.Assert(s.ctx.PevalString(`test()`), ErrorMatches, "test: expected at least 1 arguments, got 0")
+	c.Assert(s.ctx.PevalString(`test(null)`), IsNil)
+}
+
+func (s *CandySuite) TestArgumentsProxy(c *C) {
+	s.ctx.PushGlobalProxy("ms", &MyStruct{})
+	s.ctx.PushGlobalProxy("date", &myCustomProxy{})
+	s.ctx.PushGlobalGoFunction("test", func(ms *MyStruct) {})
+
+	c.Assert(s.ctx.PevalString(`test(ms)`), IsNil)
+	c.Assert(s.ctx.PevalString(`test(date)`), ErrorMatches,
+		`test: argument 1 expected \*candyjs.MyStruct, got \*candyjs.myCustomProxy`)
+	c.Assert(s.ctx.PevalString(`test({int: 42})`), IsNil)
+	c.Assert(s.ctx.PevalString(`test([])`), ErrorMatches,
+		`test: argument 1 expected \*candyjs.MyStruct, got array`)
+}
+
+func (s *CandySuite) TestArgumentsMethod(c *C) {
+	s.ctx.PushGlobalStruct("test", &MyStruct{Int: 21})
+
+	c.Assert(s.ctx.PevalString(`test.multiply("2")`), ErrorMatches,
+		"multiply: argument 1 expected int, got string")
+}
+
+func (s *CandySuite) TestArgumentsProxyMethod(c *C) {
+	s.ctx.PushGlobalProxy("test", &MyStruct{Int: 21})
+
+	c.Assert(s.ctx.PevalString(`test.multiply("2")`), ErrorMatches,
+		"multiply: argument 1 expected int, got string")
+	c.Assert(s.ctx.PevalString(`var m = test.multiply; m("2")`), ErrorMatches,
+		"multiply: argument 1 expected int, got string")
+}
+
+func (s *CandySuite) TestArgumentsLoose(c *C) {
+	s.ctx.SetStrictArguments(false)
+	s.ctx.PushGlobalGoFunction("golangMultiply", func(a, b int) int { return a * b })
+
+	c.Assert(s.ctx.PevalString(`store(golangMultiply(2))`), IsNil)
+	c.Assert(s.stored, Equals, 0.0)
+}
+
+func (s *CandySuite) TestArgs(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(args *Args) (interface{}, error) {
+		types := make([]interface{}, args.Len())
+		for i := range types {
+			types[i] = args.Type(i)
+		}
+
+		return []interface{}{
+			types, args.IsUndefined(0), args.IsUndefined(1), args.IsUndefined(5),
+			args.String(2), args.Int(3), args.String(5),
+		}, nil
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test(undefined, null, 42, "21.5", [], {}))`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		[]interface{}{"undefined", "null", "number", "string", "array", "object"},
+		true, false, false, "42", 21.0, "[object Object]",
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test())`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		[]interface{}{}, true, true, true, "", 0.0, "",
+	})
+}
+
+func (s *CandySuite) TestArgsDecode(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(args *Args) (interface{}, error) {
+		var ms MyStruct
+		if err := args.Decode(0, &ms); err != nil {
+			return nil, err
+		}
+
+		return ms.Int, nil
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test({int: 42}))`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+
+	err := s.ctx.PevalString(`test("foo")`)
+	c.Assert(err, ErrorMatches, `test: argument 1 expected candyjs.MyStruct, got string`)
+	c.Assert(ErrorCode(err), Equals, ErrorCodeTypeError)
+}
+
+func (s *CandySuite) TestArgsFunction(c *C) {
+	s.ctx.PushGlobalGoFunction("apply", func(args *Args) (interface{}, error) {
+		if fn := args.Function(0); fn != nil {
+			return fn(args.Int(1))
+		}
+
+		var m map[string]interface{}
+		if err := args.Decode(0, &m); err != nil {
+			return nil, err
+		}
+
+		return m["value"], nil
+	})
+
+	c.Assert(s.ctx.PevalString(`store([
+		apply(function(x) { return x * 2 }, 21),
+		apply({value: "foo"})
+	])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{42.0, "foo"})
+
+	c.Assert(s.ctx.PevalString(`store(apply(function() {}))`), IsNil)
+	c.Assert(s.stored, IsNil)
+
+	c.Assert(s.ctx.PevalString(`apply(function() { throw new Error("bar") })`), ErrorMatches, "Error: bar")
+}
+
+func (s *CandySuite) TestArgsThis(c *C) {
+	s.ctx.PushGlobalProxy("ms", &MyStruct{Int: 21})
+	s.ctx.PushGlobalGoFunction("test", func(args *Args) (interface{}, error) {
+		return args.This().(*MyStruct).Int * args.Int(0), nil
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test.call(ms, 2))`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+}
@@ -1,26 +1,50 @@
 package candyjs
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"time"
 	"unsafe"
 
-	"github.com/crazytyper/go-cesu8"
 	"github.com/crazytyper/go-duktape"
 )
 
-const goProxyPtrProp = "\xff" + "goProxyPtrProp"
+const (
+	goProxyPtrProp       = "\xff" + "goProxyPtrProp"
+	goProxyHandlerProp   = "\xff" + "goProxyHandlerProp"
+	goProxyMethodsProp   = "\xff" + "goProxyMethodsProp"
+	goProxyFinalizerProp = "\xff" + "goProxyFinalizerProp"
+	goProxyIdentityProp  = "\xff" + "goProxyIdentityProp"
+	goProxyBoxedProp     = "\xff" + "goProxyBoxedProp"
 
-var typeTime = reflect.TypeOf(time.Time{})
+	stashProxyFinalizer = "proxyFinalizer"
+)
+
+var (
+	typeTime      = reflect.TypeOf(time.Time{})
+	typeInterface = reflect.TypeOf((*interface{})(nil)).Elem()
+)
 
 // Context represents a Duktape thread and its call and value stacks.
 type Context struct {
-	storage      *storage
-	lastGoError  error
-	errorFactory ErrorFactoryFunc
+	storage        *storage
+	prototypes     prototypes
+	goErrors       []error
+	lastGoError    error
+	evalDepth      int
+	repanic        bool
+	looseArgs      bool
+	returns        ReturnStyle
+	resultNames    map[reflect.Type][]string
+	refs           *jsRefs
+	errorFactory   ErrorFactoryFunc
+	readOnly       bool
+	zeroCopy       bool
+	location       *time.Location
+	goCtx          context.Context
 	*duktape.Context
 }
 
@@ -28,7 +52,11 @@ type Context struct {
 func NewContext() *Context {
 	ctx := &Context{Context: duktape.New()}
 	ctx.storage = newStorage()
+	ctx.refs = &jsRefs{}
+	ctx.prototypes = make(prototypes, 0)
+	ctx.pushWrapHelpers()
 	ctx.pushGlobalCandyJSObject()
+	ctx.putProxyFinalizer()
 
 	return ctx
 }
@@ -37,20 +65,32 @@ func NewContext() *Context {
 type ErrorFactoryFunc func(ctx *Context, index int) error
 
 // SetErrorFactory sets the function used to create go errors from Javascript execptions.
-// By default `errors.New(execption.toString())` will be used.
+// By default a *JSError will be used.
 func (ctx *Context) SetErrorFactory(f ErrorFactoryFunc) {
 	ctx.errorFactory = f
 }
 
+// SetReadOnlyStructValues sets whether the struct values, not pointers, pushed
+// as proxies are read-only. By default they are boxed into an addressable
+// copy, so the methods with pointer receivers are callable and the fields of
+// the copy are writable.
+func (ctx *Context) SetReadOnlyStructValues(readOnly bool) {
+	ctx.readOnly = readOnly
+}
+
 func (ctx *Context) pushGlobalCandyJSObject() {
 	ctx.PushGlobalObject()
 	ctx.PushObject()
 	ctx.PushObject()
 	ctx.PutPropString(-2, "_functions")
-	ctx.PushGoFunction(func(pckgName string) error {
+	ctx.PushGoFunction(func(ctx *Context, pckgName string) error {
 		return ctx.pushPackage(pckgName)
 	})
 	ctx.PutPropString(-2, "require")
+	ctx.pushGoFunction(ctx.getProxyTrap)
+	ctx.PutPropString(-2, "_trap")
+	ctx.PushGoFunction(makeChan)
+	ctx.PutPropString(-2, "makeChan")
 	ctx.PutPropString(-2, "CandyJS")
 	ctx.Pop()
 
@@ -64,6 +104,35 @@ func (ctx *Context) pushGlobalCandyJSObject() {
 
 		return ptr;
 	}`)
+
+	// duktape does not invoke the defineProperty and getOwnPropertyDescriptor
+	// traps, CandyJS.defineProperty and CandyJS.getOwnPropertyDescriptor call
+	// them on the proxies and fall back to Object on any other object.
+	ctx.EvalStringNoresult(`(function() {
+		CandyJS.defineProperty = function(obj, key, desc) {
+			var trap = CandyJS._trap(obj, "defineProperty");
+			if (!trap) {
+				return Object.defineProperty(obj, key, desc);
+			}
+
+			if (!trap(obj, String(key), desc)) {
+				throw new TypeError("cannot define property '" + key + "'");
+			}
+
+			return obj;
+		};
+
+		CandyJS.getOwnPropertyDescriptor = function(obj, key) {
+			var trap = CandyJS._trap(obj, "getOwnPropertyDescriptor");
+			if (!trap) {
+				return Object.getOwnPropertyDescriptor(obj, key);
+			}
+
+			return trap(obj, String(key));
+		};
+	})()`)
+
+	ctx.pushBytesHelpers()
 }
 
 // SetRequireFunction sets the modSearch function into the Duktape JS object
@@ -79,23 +148,120 @@ func (ctx *Context) SetRequireFunction(f interface{}) int {
 }
 
 // PushGlobalType like PushType but pushed to the global object
-func (ctx *Context) PushGlobalType(name string, s interface{}) int {
+func (ctx *Context) PushGlobalType(name string, s interface{}, opts ...TypeOptions) int {
 	ctx.PushGlobalObject()
-	cons := ctx.PushType(s)
+	cons := ctx.PushType(s, opts...)
 	ctx.PutPropString(-2, name)
 	ctx.Pop()
 
 	return cons
 }
 
+// TypeOptions configures the constructor pushed by PushType.
+type TypeOptions struct {
+	// Constructor is a Go function, like `NewMyModel(a, b int) *MyModel`,
+	// called with the JS arguments. By default the constructor returns an
+	// empty instance, initialized with the fields of an optional object
+	// literal: `new MyModel({int: 42})`.
+	Constructor interface{}
+	// Statics are the functions and constants set as properties of the
+	// constructor, pushed following the rules of PushInterface.
+	Statics map[string]interface{}
+}
+
 // PushType push a constructor for the type of the given value, this constructor
 // returns an empty instance of the type. The value passed is discarded, only
 // is used for retrieve the type, instead of require pass a `reflect.Type`.
-func (ctx *Context) PushType(s interface{}) int {
-	return ctx.PushGoFunction(func() {
-		value := reflect.New(reflect.TypeOf(s))
-		ctx.PushProxy(value.Interface())
-	})
+// The constructor can be customized with a TypeOptions.
+//
+// All the proxies of the type, and of pointers to the type, share the
+// prototype of the constructor, so `instanceof` works and the functions added
+// to the prototype in JS are callable on them. The Go fields and methods take
+// precedence over the prototype properties.
+func (ctx *Context) PushType(s interface{}, opts ...TypeOptions) int {
+	var o TypeOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	t := reflect.TypeOf(s)
+
+	var cons int
+	if o.Constructor != nil {
+		cons = ctx.PushGoFunction(o.Constructor)
+	} else {
+		cons = ctx.pushGoFunction(func(*duktape.Context) int {
+			return ctx.construct(t)
+		})
+	}
+
+	for name, v := range o.Statics {
+		if err := ctx.pushValue(reflect.ValueOf(v)); err != nil {
+			ctx.PushUndefined()
+		}
+		ctx.PutPropString(cons, name)
+	}
+
+	ctx.pushPrototype(t)
+	if !ctx.HasPropString(-1, "constructor") {
+		ctx.Dup(cons)
+		ctx.PutPropString(-2, "constructor")
+	}
+	ctx.PutPropString(cons, "prototype")
+
+	return cons
+}
+
+// construct is the default constructor of PushType, pushes a new instance of
+// the given type initialized with the object literal passed as argument.
+func (ctx *Context) construct(t reflect.Type) int {
+	value := reflect.New(t).Interface()
+	if ctx.GetTop() > 0 && ctx.IsObject(0) && !ctx.IsFunction(0) {
+		if err := ctx.setFields(value, 0); err != nil {
+			return ctx.throwGoError(err)
+		}
+	}
+
+	ctx.PushProxy(value)
+	return 1
+}
+
+// setFields sets the own properties of the object at the given index to v
+// using its proxy, the properties that cannot be set are a TypeError.
+func (ctx *Context) setFields(v interface{}, index int) error {
+	proxy := ctx.getProxyFor(v)
+
+	ctx.Enum(index, duktape.EnumOwnPropertiesOnly)
+	defer ctx.Pop()
+
+	for ctx.Next(-1, true) {
+		key := ctx.SafeToString(-2)
+		value := ctx.getValueFromContext(-1, typeInterface).Interface()
+		got := ctx.typeName(-1)
+		ctx.Pop2()
+
+		ok, err := proxy.Set(v, key, value, nil)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			return fieldError(v, key, got)
+		}
+	}
+
+	return nil
+}
+
+// fieldError returns the TypeError of a field of v that cannot be set to a
+// value of the JS type got.
+func fieldError(v interface{}, key, got string) error {
+	name := reflect.Indirect(reflect.ValueOf(v)).Type().Name()
+	if f, err := p.Property(v, key); err == nil && f.CanSet() {
+		return errorf(ErrorCodeTypeError, "%s: field %s expected %s, got %s", name, key, f.Type(), got)
+	}
+
+	return errorf(ErrorCodeTypeError, "%s: field %s cannot be set", name, key)
 }
 
 // PushGlobalProxy like PushProxy but pushed to the global object
@@ -110,45 +276,452 @@ func (ctx *Context) PushGlobalProxy(name string, v interface{}) int {
 
 // PushProxy push a proxified pointer of the given value to the stack, this
 // refence will be stored on an internal storage. The pushed objects has
-// the exact same methods and properties from the original value.
+// the exact same methods and properties from the original value. Pushing the
+// same pointer again pushes the same object, while it has not been collected.
+//
+// Values implementing Proxy handle their own traps, values implementing
+// ProxyProvider are handled by the returned Proxy and any other value by a
+// ReflectProxy. Besides the traps defined by Proxy the optional interfaces
+// Deleter, Describer, Caller and Constructor are honored. The ReflectProxy
+// implements all of them, proxied Go functions can be called and used with
+// `new`.
 // http://duktape.org/guide.html#virtualization-proxy-object
 func (ctx *Context) PushProxy(v interface{}) int {
+	if heapptr := ctx.storage.getProxy(v); heapptr != nil {
+		ctx.PushHeapptr(heapptr)
+		return ctx.GetTopIndex()
+	}
+
+	// struct values are boxed, the Go functions receive a copy of the box
+	var boxed bool
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Struct && !ctx.readOnly {
+		box := reflect.New(rv.Type())
+		box.Elem().Set(rv)
+		v, boxed = box.Interface(), true
+	}
 
 	ptr := ctx.storage.add(v)
 
-	proxy, ok := v.(Proxy)
-	if !ok {
-		proxy = p // fallback to the default proxy that uses package reflect
+	proxy := ctx.getProxyFor(v)
+
+	// the target of callable proxies must be a function
+	var obj int
+	if isCallableProxy(proxy, v) {
+		obj = ctx.pushGoFunction(func(*duktape.Context) int { return 0 })
+	} else {
+		obj = ctx.PushObject()
 	}
 
-	obj := ctx.PushObject()
 	ctx.PushPointer(ptr)
 	ctx.PutPropString(-2, goProxyPtrProp)
+	ctx.setProxyFinalizer(obj)
+	ctx.setPrototype(obj, v)
+	if boxed {
+		ctx.PushTrue()
+		ctx.PutPropString(obj, goProxyBoxedProp)
+	}
 
 	ctx.PushGlobalObject()
 	ctx.GetPropString(-1, "Proxy")
 	ctx.Dup(obj)
+	ctx.pushProxyHandler(proxy)
+	ctx.Dup(-1)
+	ctx.PutPropString(obj, goProxyHandlerProp)
+	ctx.New(2)
+
+	// the target references its proxy, this keeps the heap pointer of the
+	// proxy valid until the finalizer of the target has been called.
+	ctx.Dup(-1)
+	ctx.PutPropString(obj, goProxyIdentityProp)
+	ctx.storage.putProxy(v, ptr, ctx.GetHeapptr(-1))
+
+	ctx.Remove(-2)
+	ctx.Remove(-2)
 
+	ctx.PushPointer(ptr)
+	ctx.PutPropString(-2, goProxyPtrProp)
+
+	return obj
+}
+
+func (ctx *Context) getProxyFor(v interface{}) Proxy {
+	switch pv := v.(type) {
+	case Proxy:
+		return pv
+	case ProxyProvider:
+		return pv.JSProxy()
+	}
+
+	if reflect.ValueOf(v).Kind() == reflect.Chan {
+		return &chanProxy{ctx: ctx}
+	}
+
+	switch v.(type) {
+	case io.Reader, io.Writer:
+		return newStreamProxy(v, defaultProxy)
+	}
+
+	return defaultProxy // fallback to the default proxy that uses package reflect
+}
+
+func (ctx *Context) pushProxyHandler(proxy Proxy) {
 	ctx.PushObject()
-	ctx.PushGoFunction(proxy.Enumerate)
+	ctx.pushTrap(proxy.Enumerate)
 	ctx.PutPropString(-2, "enumerate")
-	ctx.PushGoFunction(proxy.Enumerate)
+	ctx.pushTrap(proxy.Enumerate)
 	ctx.PutPropString(-2, "ownKeys")
-	ctx.PushGoFunction(proxy.Get)
+	ctx.pushGoFunction(func(*duktape.Context) int {
+		return ctx.callGetTrap(proxy)
+	})
 	ctx.PutPropString(-2, "get")
-	ctx.PushGoFunction(proxy.Set)
+	ctx.pushTrap(proxy.Set)
 	ctx.PutPropString(-2, "set")
-	ctx.PushGoFunction(proxy.Has)
+	ctx.pushGoFunction(func(*duktape.Context) int {
+		return ctx.callHasTrap(proxy)
+	})
 	ctx.PutPropString(-2, "has")
-	ctx.New(2)
 
-	ctx.Remove(-2)
-	ctx.Remove(-2)
+	if d, ok := proxy.(Deleter); ok {
+		ctx.pushTrap(d.DeleteProperty)
+		ctx.PutPropString(-2, "deleteProperty")
+	}
 
-	ctx.PushPointer(ptr)
-	ctx.PutPropString(-2, goProxyPtrProp)
+	if d, ok := proxy.(Describer); ok {
+		ctx.pushGoFunction(func(*duktape.Context) int {
+			return ctx.callDescriberTrap(d, true)
+		})
+		ctx.PutPropString(-2, "defineProperty")
+		ctx.pushGoFunction(func(*duktape.Context) int {
+			return ctx.callDescriberTrap(d, false)
+		})
+		ctx.PutPropString(-2, "getOwnPropertyDescriptor")
+	}
 
-	return obj
+	if c, ok := proxy.(Caller); ok {
+		ctx.pushGoFunction(func(*duktape.Context) int {
+			return ctx.callFunction(c.Apply, []reflect.Value{
+				ctx.getTrapTarget(),
+				ctx.getValueFromContext(1, typeInterface),
+				reflect.ValueOf(ctx.getArrayValues(2)),
+			})
+		})
+		ctx.PutPropString(-2, "apply")
+	}
+
+	if c, ok := proxy.(Constructor); ok {
+		ctx.pushGoFunction(func(*duktape.Context) int {
+			return ctx.callFunction(c.Construct, []reflect.Value{
+				ctx.getTrapTarget(),
+				reflect.ValueOf(ctx.getArrayValues(1)),
+			})
+		})
+		ctx.PutPropString(-2, "construct")
+	}
+}
+
+// pushTrap pushes a trap calling the given function, see getTrapArgs. The
+// traps with a key are not called for the symbols, false is returned.
+func (ctx *Context) pushTrap(f interface{}) {
+	hasKey := reflect.TypeOf(f).NumIn() > 1
+	ctx.pushGoFunction(func(*duktape.Context) int {
+		if hasKey && ctx.isSymbol(1) {
+			ctx.PushFalse()
+			return 1
+		}
+
+		return ctx.callFunction(f, ctx.getTrapArgs(f))
+	})
+}
+
+// getTrapArgs is like getFunctionArgs but the first argument is the target as
+// stored, the boxed struct values are passed as pointers.
+func (ctx *Context) getTrapArgs(f interface{}) []reflect.Value {
+	args := ctx.getFunctionArgs(f)
+	args[0] = ctx.getTrapTarget()
+	return args
+}
+
+// getTrapTarget returns the value proxied by the target of a trap.
+func (ctx *Context) getTrapTarget() reflect.Value {
+	t := ctx.getProxy(0)
+	return reflect.ValueOf(&t).Elem()
+}
+
+// callGetTrap handles the `get` (target, key, receiver) trap, the methods
+// returned by proxies implementing methodProxy are pushed once per target and
+// cached, so `obj.method === obj.method` holds.
+//
+// The keys unknown to the proxy are looked up in the prototype of the target.
+func (ctx *Context) callGetTrap(proxy Proxy) int {
+	if ctx.isSymbol(1) && !ctx.isSymbolToPrimitive(1) {
+		ctx.PushUndefined()
+		return 1
+	}
+
+	// the key is read as a string by getTrapArgs
+	toPrimitive := ctx.isSymbolToPrimitive(1)
+	get := namedGet(proxy)
+	args := ctx.getTrapArgs(proxy.Get)
+	if toPrimitive {
+		args[1] = reflect.ValueOf(ToPrimitiveKey)
+		return ctx.callFunction(get, args)
+	}
+
+	if ctx.pushPrototypeProp(0, args[1].String()) {
+		if !proxy.Has(args[0].Interface(), args[1].String()) {
+			return 1
+		}
+		ctx.Pop()
+	}
+
+	if mp, ok := proxy.(methodProxy); !ok || !mp.isMethod(args[0].Interface(), args[1].String()) {
+		return ctx.callFunction(get, args)
+	}
+
+	if !ctx.GetPropString(0, goProxyMethodsProp) {
+		ctx.Pop()
+		ctx.PushObject()
+		ctx.Dup(-1)
+		ctx.PutPropString(0, goProxyMethodsProp)
+	}
+
+	methods := ctx.NormalizeIndex(-1)
+	if ctx.GetPropString(methods, args[1].String()) {
+		return 1
+	}
+	ctx.Pop()
+
+	if rc := ctx.callFunction(get, args); rc != 1 || ctx.isThrownGoError(-1) {
+		return rc
+	}
+
+	ctx.Dup(-1)
+	ctx.PutPropString(methods, args[1].String())
+	return 1
+}
+
+// namedFunc is a function pushed with the given name, the name used by the
+// errors of its arguments.
+type namedFunc struct {
+	name string
+	fn   interface{}
+}
+
+// namedGet returns the Get of the proxy, the functions returned are named
+// after the key they were got with.
+func namedGet(proxy Proxy) func(t interface{}, k string, recv interface{}) (interface{}, error) {
+	return func(t interface{}, k string, recv interface{}) (interface{}, error) {
+		v, err := proxy.Get(t, k, recv)
+		if err == nil && v != nil && reflect.TypeOf(v).Kind() == reflect.Func {
+			return namedFunc{name: k, fn: v}, nil
+		}
+
+		return v, err
+	}
+}
+
+// callHasTrap handles the `has` (target, key) trap, the properties of the
+// prototype of the target are included.
+func (ctx *Context) callHasTrap(proxy Proxy) int {
+	if ctx.isSymbol(1) {
+		ctx.PushFalse()
+		return 1
+	}
+
+	args := ctx.getTrapArgs(proxy.Has)
+	has := proxy.Has(args[0].Interface(), args[1].String())
+	if !has {
+		has = ctx.pushPrototypeProp(0, args[1].String())
+	}
+
+	ctx.PushBoolean(has)
+	return 1
+}
+
+// symbolToPrimitive is the internal representation of `Symbol.toPrimitive`,
+// the well-known symbols are a 0x81 byte, the description and a 0xff byte.
+const symbolToPrimitive = "\x81Symbol.toPrimitive\xff"
+
+// isSymbol returns true if the value at the given index is a symbol, duktape
+// represents the symbols as strings starting with an invalid UTF-8 byte,
+// they are not received as such in Go.
+func (ctx *Context) isSymbol(index int) bool {
+	if !ctx.IsString(index) {
+		return false
+	}
+
+	s := ctx.GetString(index)
+	if !strings.HasPrefix(s, "\ufffd") {
+		return false
+	}
+
+	ctx.Context.PushString(s)
+	defer ctx.Pop()
+
+	return !ctx.StrictEquals(index, -1)
+}
+
+// isSymbolToPrimitive returns true if the value at the given index is
+// `Symbol.toPrimitive`, used by duktape to coerce the proxies, the trap key
+// is ToPrimitiveKey.
+func (ctx *Context) isSymbolToPrimitive(index int) bool {
+	index = ctx.NormalizeIndex(index)
+	ctx.Context.PushString(symbolToPrimitive)
+	defer ctx.Pop()
+
+	return ctx.StrictEquals(index, -1)
+}
+
+// putProxyFinalizer stores the finalizer shared by all the proxy targets into
+// the global stash.
+func (ctx *Context) putProxyFinalizer() {
+	ctx.PushGlobalStash()
+	// run by the garbage collections, it throws nothing and must not reset
+	// GoErrors, see pushNative.
+	ctx.Context.PushGoFunction(ctx.finalizeProxy)
+	ctx.PutPropString(-2, stashProxyFinalizer)
+	ctx.Pop()
+}
+
+// setProxyFinalizer sets the proxy finalizer to the target at the given index,
+// a previous finalizer is kept and called by finalizeProxy.
+func (ctx *Context) setProxyFinalizer(index int) {
+	ctx.GetFinalizer(index)
+	if ctx.IsUndefined(-1) {
+		ctx.Pop()
+	} else {
+		ctx.PutPropString(index, goProxyFinalizerProp)
+	}
+
+	ctx.PushGlobalStash()
+	ctx.GetPropString(-1, stashProxyFinalizer)
+	ctx.SetFinalizer(index)
+	ctx.Pop()
+}
+
+// finalizeProxy releases the proxied value and the cached methods of a
+// collected target, or the Go error of a collected JS error.
+func (ctx *Context) finalizeProxy(*duktape.Context) int {
+	if ptr := ctx.getProxyPtrProp(0); ptr != nil {
+		ctx.storage.remove(ptr)
+	}
+
+	if ctx.GetPropString(0, goErrorProp) && ctx.IsPointer(-1) {
+		ctx.storage.remove(ctx.GetPointer(-1))
+	}
+	ctx.Pop()
+
+	ctx.DelPropString(0, goProxyMethodsProp)
+	ctx.DelPropString(0, goProxyIdentityProp)
+
+	if ctx.GetPropString(0, goProxyFinalizerProp) {
+		ctx.Dup(0)
+		ctx.Call(1)
+	}
+
+	return 0
+}
+
+// callDescriberTrap handles the `defineProperty` (target, key, descriptor) and
+// `getOwnPropertyDescriptor` (target, key) traps.
+func (ctx *Context) callDescriberTrap(d Describer, define bool) int {
+	if ctx.isSymbol(1) {
+		if define {
+			ctx.PushFalse()
+		} else {
+			ctx.PushUndefined()
+		}
+
+		return 1
+	}
+
+	t := ctx.getTrapTarget().Interface()
+	k := ctx.SafeToString(1)
+
+	if define {
+		desc := &PropertyDescriptor{}
+		if ctx.IsObject(2) {
+			if desc.HasValue = ctx.HasPropString(2, "value"); desc.HasValue {
+				ctx.GetPropString(2, "value")
+				desc.Value = ctx.getValueFromContext(-1, typeInterface).Interface()
+				ctx.Pop()
+			}
+
+			desc.Writable = ctx.getBooleanProp(2, "writable")
+			desc.Enumerable = ctx.getBooleanProp(2, "enumerable")
+			desc.Configurable = ctx.getBooleanProp(2, "configurable")
+		}
+
+		return ctx.callFunction(d.DefineProperty, []reflect.Value{
+			reflect.ValueOf(&t).Elem(), reflect.ValueOf(k), reflect.ValueOf(desc),
+		})
+	}
+
+	desc, err := d.GetOwnPropertyDescriptor(t, k)
+	if err != nil {
+		return ctx.throwGoError(err)
+	}
+
+	if desc == nil {
+		ctx.PushUndefined()
+		return 1
+	}
+
+	obj := ctx.PushObject()
+	if desc.HasValue {
+		if err := ctx.pushValue(reflect.ValueOf(desc.Value)); err != nil {
+			return duktape.ErrRetInternal
+		}
+		ctx.PutPropString(obj, "value")
+	}
+
+	ctx.PushBoolean(desc.Writable)
+	ctx.PutPropString(obj, "writable")
+	ctx.PushBoolean(desc.Enumerable)
+	ctx.PutPropString(obj, "enumerable")
+	ctx.PushBoolean(desc.Configurable)
+	ctx.PutPropString(obj, "configurable")
+
+	return 1
+}
+
+func (ctx *Context) getBooleanProp(index int, key string) bool {
+	defer ctx.Pop()
+	ctx.GetPropString(index, key)
+	return ctx.ToBoolean(-1)
+}
+
+// getArrayValues returns the elements of the array at the given index, the
+// proxies are returned as is.
+func (ctx *Context) getArrayValues(index int) []interface{} {
+	values := make([]interface{}, 0)
+	if !ctx.IsArray(index) {
+		return values
+	}
+
+	index = ctx.NormalizeIndex(index)
+	for i := 0; i < ctx.GetLength(index); i++ {
+		ctx.GetPropIndex(index, uint(i))
+		values = append(values, ctx.getValueFromContext(-1, typeInterface).Interface())
+		ctx.Pop()
+	}
+
+	return values
+}
+
+// getProxyTrap backs `CandyJS._trap(obj, name)`, returns the named trap of a
+// proxy pushed by PushProxy or undefined.
+func (ctx *Context) getProxyTrap(*duktape.Context) int {
+	if !ctx.IsObject(0) {
+		return 0
+	}
+
+	if !ctx.GetPropString(0, goProxyHandlerProp) {
+		return 0
+	}
+
+	ctx.GetPropString(-1, ctx.SafeToString(1))
+	return 1
 }
 
 // PushGlobalStruct like PushStruct but pushed to the global object
@@ -213,8 +786,9 @@ func (ctx *Context) pushStructMethods(obj int, t reflect.Type, v reflect.Value)
 			continue
 		}
 
-		ctx.PushGoFunction(v.Method(i).Interface())
-		ctx.PutPropString(obj, nameToJavaScript(methodName))
+		name := nameToJavaScript(methodName)
+		ctx.pushNative(ctx.wrapFunction(name, v.Method(i).Interface()))
+		ctx.PutPropString(obj, name)
 
 	}
 }
@@ -229,8 +803,10 @@ func (ctx *Context) PushGlobalInterface(name string, v interface{}) error {
 //  - Bool
 //  - Int, Int8, Int16, Int32, Uint, Uint8, Uint16, Uint32 and Uint64
 //  - Float32 and Float64
-//  - Strings and []byte
+//  - Strings
+//  - []byte, pushed as an Uint8Array copy
 //  - Structs
+//  - time.Time, pushed as a Date
 //  - Functions with any signature
 //
 // Please read carefully the following notes:
@@ -277,7 +853,7 @@ func (ctx *Context) pushValue(v reflect.Value) error {
 	case reflect.Float64:
 		ctx.PushNumber(v.Float())
 	case reflect.String:
-		ctx.PushString(string(cesu8.EncodeString(v.String())))
+		ctx.PushString(v.String())
 	case reflect.Struct:
 		// also use `Date` for `time.Time` aliases
 		tv := v.Type()
@@ -287,12 +863,9 @@ func (ctx *Context) pushValue(v reflect.Value) error {
 
 		switch i := v.Interface().(type) {
 		case time.Time:
-			// new Date(...)
-			ctx.PushGlobalObject()
-			ctx.GetPropString(-1, "Date")
-			ctx.PushNumber(float64(i.Unix()*1000 + int64(i.Nanosecond()/int(time.Millisecond))))
-			ctx.New(1)
-			ctx.Remove(-2) // remove the global object from the stack
+			ctx.pushTime(i)
+		case namedFunc:
+			ctx.pushNative(ctx.wrapFunction(i.name, i.fn))
 
 		default:
 			ctx.PushProxy(i)
@@ -300,6 +873,8 @@ func (ctx *Context) pushValue(v reflect.Value) error {
 
 	case reflect.Func:
 		ctx.PushGoFunction(v.Interface())
+	case reflect.Chan:
+		ctx.PushProxy(v.Interface())
 	case reflect.Ptr:
 		if v.Elem().Kind() == reflect.Struct {
 			ctx.PushProxy(v.Interface())
@@ -309,7 +884,7 @@ func (ctx *Context) pushValue(v reflect.Value) error {
 		return ctx.pushValue(v.Elem())
 	case reflect.Slice:
 		if v.Type().Elem().Kind() == reflect.Uint8 {
-			ctx.PushString(string(v.Interface().([]byte)))
+			ctx.pushBytes(v.Bytes())
 			return nil
 		}
 
@@ -342,7 +917,7 @@ func (ctx *Context) pushValue(v reflect.Value) error {
 			return err
 		}
 
-		ctx.PushString(string(cesu8.EncodeString(string(js))))
+		ctx.PushString(string(js))
 		ctx.JsonDecode(-1)
 	}
 
@@ -376,7 +951,7 @@ func (ctx *Context) pushValues(vs []reflect.Value) error {
 
 // PushGlobalGoFunction like PushGoFunction but pushed to the global object
 func (ctx *Context) PushGlobalGoFunction(name string, f interface{}) (int, error) {
-	return ctx.Context.PushGlobalGoFunction(name, ctx.wrapFunction(f))
+	return ctx.pushGlobalNative(name, ctx.wrapFunction(name, f))
 }
 
 // PushGoFunction push a native Go function of any signature to the stack.
@@ -386,13 +961,16 @@ func (ctx *Context) PushGlobalGoFunction(name string, f interface{}) (int, error
 // The most common types are supported as input arguments, also the variadic
 // functions can be used.
 //
-// You can use JS functions as arguments but you should wrapper it with the
-// helper `CandyJS.proxy`. Example:
+// You can use JS functions as arguments, they are pinned in the heap stash
+// until the Go function is collected, so the Go function can be called at
+// any later time, e.g. stored as a callback. Example:
 // 	ctx.PushGlobalGoFunction("test", func(fn func(int, int) int) {
 //		...
 //	})
 //
-//	ctx.PevalString(`test(CandyJS.proxy(function(a, b) { return a * b; }));`)
+//	ctx.PevalString(`test(function(a, b) { return a * b; });`)
+//
+// The helper `CandyJS.proxy`, that was required before, is no longer needed.
 //
 // The structs can be delivered to the functions in three ways:
 //  - In-line representation as plain JS objects: `{'int':42}`
@@ -401,6 +979,11 @@ func (ctx *Context) PushGlobalGoFunction(name string, f interface{}) (int, error
 //
 // All other types are loaded into Go using `json.Unmarshal` internally
 //
+// The leading parameters of types `*candyjs.Context`, `context.Context` and
+// `candyjs.This` are filled in without consuming JS arguments, see This. The
+// functions of the form `func(*candyjs.Args) (interface{}, error)` read the
+// raw arguments, see Args.
+//
 // The following types are not supported chans, complex64 or complex128, and
 // the types rune, byte and arrays are not tested.
 //
@@ -417,15 +1000,29 @@ func (ctx *Context) PushGlobalGoFunction(name string, f interface{}) (int, error
 // All the non erros returning values are pushed following the same rules of
 // `PushInterface` method
 func (ctx *Context) PushGoFunction(f interface{}) int {
-	return ctx.Context.PushGoFunction(ctx.wrapFunction(f))
+	return ctx.pushNative(ctx.wrapFunction(funcName(f), f))
 }
 
 // GetValue gets and marshals the value at the specified stack index.
 // Takes special care for proxies.
 func (ctx *Context) GetValue(index int, value interface{}) error {
 	t := reflect.ValueOf(value).Elem()
-	if proxy := ctx.getProxy(index); proxy != nil {
-		t.Set(reflect.ValueOf(proxy)) // return the value as is
+	if proxy, ok := ctx.getProxyValue(index, t.Type()); ok {
+		t.Set(proxy) // return the value as is
+		return nil
+	}
+
+	if b, ok := ctx.getBytesValue(index, t.Type(), false); ok {
+		t.Set(b)
+		return nil
+	}
+
+	if tm, ok, err := ctx.getTimeValue(index, t.Type()); ok {
+		if err != nil {
+			return err
+		}
+
+		t.Set(tm)
 		return nil
 	}
 
@@ -443,39 +1040,74 @@ func (ctx *Context) GetValue(index int, value interface{}) error {
 }
 
 // LastGoError returns the last error returned by a GO function.
+//
+// Deprecated: use GoErrors, the errors are also returned by the evaluations.
 func (ctx *Context) LastGoError() error {
 	return ctx.lastGoError
 }
 
 // ConsumeLastGoError returns the last error returned by a GO function and clears it.
+//
+// Deprecated: use GoErrors, the errors are also returned by the evaluations.
 func (ctx *Context) ConsumeLastGoError() error {
 	err := ctx.lastGoError
 	ctx.lastGoError = nil
 	return err
 }
 
-func (ctx *Context) wrapFunction(f interface{}) func(ctx *duktape.Context) int {
+func (ctx *Context) wrapFunction(name string, f interface{}) func(ctx *duktape.Context) int {
 	tbaContext := ctx
-	return func(ctx *duktape.Context) int {
-		args := tbaContext.getFunctionArgs(f)
-		return tbaContext.callFunction(f, args)
+	returns, results := ReturnDefault, []string(nil)
+	if fn, ok := f.(Func); ok {
+		f, returns, results = fn.Fn, fn.Returns, fn.Results
 	}
+
+	if reflect.TypeOf(f) == typeArgsFunc {
+		return ctx.recoverPanics(func(ctx *duktape.Context) int {
+			args := &Args{ctx: tbaContext, name: name}
+			style := tbaContext.returnStyle(returns)
+			return tbaContext.callFunctionReturning(f, []reflect.Value{reflect.ValueOf(args)}, style, nil)
+		})
+	}
+
+	return ctx.recoverPanics(func(ctx *duktape.Context) int {
+		if !tbaContext.looseArgs {
+			if err := tbaContext.checkArgs(name, f); err != nil {
+				return tbaContext.throwGoError(err)
+			}
+		}
+
+		args := tbaContext.getFunctionArgs(f)
+		style := tbaContext.returnStyle(returns)
+		names := tbaContext.resultNamesOf(reflect.TypeOf(f), results)
+		return tbaContext.callFunctionReturning(f, args, style, names)
+	})
 }
 
 func (ctx *Context) getFunctionArgs(f interface{}) []reflect.Value {
 	def := reflect.ValueOf(f).Type()
 	isVariadic := def.IsVariadic()
 	inCount := def.NumIn()
+	injected := injectedCount(def)
 
 	top := ctx.GetTopIndex()
 
-	var args []reflect.Value
+	args := ctx.injectArgs(def, injected)
 	for index := 0; index <= top; index++ {
 		var t reflect.Type
-		if (index+1) < inCount || (index < inCount && !isVariadic) {
-			t = def.In(index)
+		if in := index + injected; (in+1) < inCount || (in < inCount && !isVariadic) {
+			t = def.In(in)
 		} else if isVariadic {
 			t = def.In(inCount - 1).Elem()
+		} else {
+			break // extra args are ignored
+		}
+
+		if ctx.zeroCopy && t != nil && t.Kind() == reflect.Slice {
+			if b, ok := ctx.getBytesValue(index, t, true); ok {
+				args = append(args, b)
+				continue
+			}
 		}
 
 		args = append(args, ctx.getValueFromContext(index, t))
@@ -498,15 +1130,18 @@ func (ctx *Context) getFunctionArgs(f interface{}) []reflect.Value {
 }
 
 func (ctx *Context) getValueFromContext(index int, t reflect.Type) reflect.Value {
-	if proxy := ctx.getProxy(index); proxy != nil {
-		return reflect.ValueOf(proxy)
+	if proxy, ok := ctx.getProxyValue(index, t); ok {
+		return proxy
 	}
 
 	if ctx.IsFunction(index) && t.Kind() == reflect.Func {
+		// the function is pinned, the Go function can be called once this call
+		// returns, e.g. stored as a callback.
+		fn := ctx.pinValue(index)
 		return reflect.MakeFunc(t,
 			func(args []reflect.Value) (results []reflect.Value) {
 				// Bring the function back to the top of the stack
-				ctx.Dup(index)
+				fn.push()
 
 				// Followed by the arguments passed to it
 				for _, v := range args {
@@ -520,7 +1155,9 @@ func (ctx *Context) getValueFromContext(index int, t reflect.Type) reflect.Value
 				// http://duktape.org/api.html#duk_pcall
 				defer ctx.Pop()
 
-				if ret := ctx.Pcall(len(args)); ret != duktape.ExecSuccess {
+				var ret int
+				ctx.enterJS(func() { ret = ctx.Pcall(len(args)) })
+				if ret != duktape.ExecSuccess {
 					return ctx.getCallResultError(t, ctx.getError(-1))
 				}
 
@@ -537,9 +1174,43 @@ func (ctx *Context) getValueFromContext(index int, t reflect.Type) reflect.Value
 		return reflect.ValueOf(ctx.getError(index))
 	}
 
+	if b, ok := ctx.getBytesValue(index, t, false); ok {
+		return b
+	}
+
+	if tm, ok, err := ctx.getTimeValue(index, t); ok {
+		if err != nil {
+			return reflect.Zero(t) // rejected by checkArgs
+		}
+
+		return tm
+	}
+
+	if ctx.isJSWriter(index, t) {
+		return reflect.ValueOf(&jsWriter{ctx: ctx, obj: ctx.pinValue(index)})
+	}
+
 	return ctx.getValueUsingJSON(index, t)
 }
 
+// getProxyValue returns the value proxied by the object at the given index, the
+// boxed struct values are returned as values unless t requires the pointer.
+func (ctx *Context) getProxyValue(index int, t reflect.Type) (reflect.Value, bool) {
+	proxy := ctx.getProxy(index)
+	if proxy == nil {
+		return reflect.Value{}, false
+	}
+
+	v := reflect.ValueOf(proxy)
+	if ctx.hasHiddenProp(index, goProxyBoxedProp) {
+		if t == nil || v.Elem().Type().AssignableTo(t) {
+			return v.Elem(), true
+		}
+	}
+
+	return v, true
+}
+
 func (ctx *Context) getProxy(index int) interface{} {
 	if !ctx.IsObject(index) {
 		return nil
@@ -554,11 +1225,15 @@ func (ctx *Context) getProxy(index int) interface{} {
 }
 
 func (ctx *Context) getError(index int) error {
+	if err := ctx.getGoError(index); err != nil {
+		return err
+	}
+
 	factory := ctx.errorFactory
 	if factory != nil {
 		return factory(ctx, index)
 	}
-	return errors.New(ctx.SafeToString(index))
+	return ctx.getJSError(index)
 }
 
 func (ctx *Context) getFunction(index int, t reflect.Type) reflect.Value {
@@ -601,16 +1276,30 @@ func (ctx *Context) getCallResult(t reflect.Type) []reflect.Value {
 		// returns just an error
 	} else if count == 1 {
 		result = append(result, ctx.getValueFromContext(-1, t.Out(0)))
+	} else if names := ctx.resultNames[t]; len(names) >= count && !ctx.IsArray(-1) && ctx.IsObject(-1) {
+		// an object with the named results, see SetResultNames
+		idx := ctx.NormalizeIndex(-1)
+		for i := 0; i < count; i++ {
+			ctx.GetPropString(idx, names[i])
+			result = append(result, ctx.getValueFromContext(-1, t.Out(i)))
+			ctx.Pop()
+		}
 	} else {
 		actualCount := ctx.GetLength(-1)
 		if actualCount != count {
-			panic(fmt.Sprintf("Invalid count of return value on proxied function. Expected %d had %d", actualCount, count))
+			err := errorf(ErrorCodeTypeError, "Invalid count of return value on proxied function. Expected %d had %d", count, actualCount)
+			if hasErrorArg {
+				return ctx.getCallResultError(t, err)
+			}
+
+			panic(err)
 		}
 
 		idx := ctx.NormalizeIndex(-1)
 		for i := 0; i < count; i++ {
 			ctx.GetPropIndex(idx, uint(i))
 			result = append(result, ctx.getValueFromContext(-1, t.Out(i)))
+			ctx.Pop()
 		}
 	}
 	if hasErrorArg {
@@ -667,23 +1356,32 @@ func (ctx *Context) getValueUsingJSON(index int, t reflect.Type) reflect.Value {
 }
 
 func (ctx *Context) callFunction(f interface{}, args []reflect.Value) int {
-	ctx.lastGoError = nil
+	return ctx.callFunctionReturning(f, args, ReturnThrow, nil)
+}
 
-	var err error
+// callFunctionReturning calls f returning its results in the given style, see
+// ReturnStyle, the several results are named by names if any.
+func (ctx *Context) callFunctionReturning(f interface{}, args []reflect.Value, style ReturnStyle, names []string) int {
 	out := reflect.ValueOf(f).Call(args)
-	out, err = ctx.handleReturnError(out)
+	ctx.lastGoError = nil
+	if style != ReturnThrow {
+		return ctx.pushResults(out, style, names)
+	}
+
+	out, err := ctx.handleReturnError(out)
 
 	if err != nil {
-		ctx.lastGoError = err
-		return duktape.ErrRetError
+		return ctx.throwGoError(err)
 	}
 
-	ctx.lastGoError = nil
-
 	if len(out) == 0 {
 		return 1
 	}
 
+	if len(out) > 1 && len(names) > 0 {
+		return ctx.pushResults(out, ReturnObject, names)
+	}
+
 	if len(out) > 1 {
 		err = ctx.pushValues(out)
 	} else {
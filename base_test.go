@@ -138,6 +138,91 @@ func (s *CandySuite) TestPushGlobalProxy_Has(c *C) {
 	c.Assert(s.stored, Equals, false)
 }
 
+func (s *CandySuite) TestPushGlobalProxy_Delete(c *C) {
+	m := &MyStruct{Int: 42, String: "foo"}
+	s.ctx.PushGlobalProxy("test", m)
+
+	c.Assert(s.ctx.PevalString(`store(delete test.int)`), IsNil)
+	c.Assert(s.stored, Equals, true)
+	c.Assert(m.Int, Equals, 0)
+	c.Assert(m.String, Equals, "foo")
+
+	c.Assert(s.ctx.PevalString(`store(delete test.multiply)`), IsNil)
+	c.Assert(s.stored, Equals, false)
+}
+
+func (s *CandySuite) TestPushGlobalProxy_DefineProperty(c *C) {
+	m := &MyStruct{Int: 42}
+	s.ctx.PushGlobalProxy("test", m)
+
+	c.Assert(s.ctx.PevalString(`CandyJS.defineProperty(test, "int", {value: 21})`), IsNil)
+	c.Assert(m.Int, Equals, 21)
+
+	c.Assert(s.ctx.PevalString(`
+		try {
+			CandyJS.defineProperty(test, "multiply", {value: 21});
+		} catch(err) {
+			store(err instanceof TypeError);
+		}
+	`), IsNil)
+	c.Assert(s.stored, Equals, true)
+
+	c.Assert(s.ctx.PevalString(`
+		var obj = {};
+		CandyJS.defineProperty(obj, "foo", {value: 42});
+		store(obj.foo)
+	`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+}
+
+func (s *CandySuite) TestPushGlobalProxy_GetOwnPropertyDescriptor(c *C) {
+	s.ctx.PushGlobalProxy("test", &MyStruct{Int: 42})
+
+	c.Assert(s.ctx.PevalString(`
+		var d = CandyJS.getOwnPropertyDescriptor(test, "int");
+		store([d.value, d.writable, d.enumerable, d.configurable])
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{42.0, true, true, true})
+
+	c.Assert(s.ctx.PevalString(`store(CandyJS.getOwnPropertyDescriptor(test, "foo") === undefined)`), IsNil)
+	c.Assert(s.stored, Equals, true)
+
+	c.Assert(s.ctx.PevalString(`store(CandyJS.getOwnPropertyDescriptor({foo: 42}, "foo").value)`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+}
+
+func (s *CandySuite) TestPushGlobalProxy_ObjectBuiltins(c *C) {
+	c.Assert(s.ctx.PevalString(`store([
+		/native code/.test(String(Object.defineProperty)),
+		/native code/.test(String(Object.getOwnPropertyDescriptor))
+	])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, true})
+}
+
+func (s *CandySuite) TestPushGlobalProxy_ObjectBuiltinsSkipTraps(c *C) {
+	m := &MyStruct{Int: 42}
+	s.ctx.PushGlobalProxy("test", m)
+
+	// duktape does not invoke the traps, the target of the proxy is used
+	c.Assert(s.ctx.PevalString(`store(Object.getOwnPropertyDescriptor(test, "int") === undefined)`), IsNil)
+	c.Assert(s.stored, Equals, true)
+
+	c.Assert(s.ctx.PevalString(`Object.defineProperty(test, "int", {value: 21}); store(test.int)`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+	c.Assert(m.Int, Equals, 42)
+}
+
+func (s *CandySuite) TestPushGlobalProxy_Function(c *C) {
+	s.ctx.PushGlobalProxy("test", func(a, b int) int { return a * b })
+	s.ctx.PushGlobalProxy("newStruct", func(i int) *MyStruct { return &MyStruct{Int: i} })
+
+	c.Assert(s.ctx.PevalString(`store(test(6, 7))`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+
+	c.Assert(s.ctx.PevalString(`store(new newStruct(21).multiply(2))`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+}
+
 func (s *CandySuite) TestPushGlobalProxy_Nested(c *C) {
 	s.ctx.PushGlobalProxy("test", &MyStruct{
 		Int:     42,
@@ -155,6 +240,53 @@ func (s *CandySuite) TestPushGlobalProxy_Nested(c *C) {
 	c.Assert(s.stored, DeepEquals, []interface{}{42.0, 84.0, 21.0, 63.0})
 }
 
+func (s *CandySuite) TestPushGlobalProxy_MethodIdentity(c *C) {
+	s.ctx.PushGlobalProxy("test", &MyStruct{Int: 21})
+
+	c.Assert(s.ctx.PevalString(`
+		var m = test.multiply;
+		test.int = 42;
+		store([test.multiply === test.multiply, m === test.multiply, m(2)])
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, true, 84.0})
+}
+
+func (s *CandySuite) TestPushProxy_Finalize(c *C) {
+	s.ctx.PevalString(`Duktape.gc()`)
+	count := s.ctx.storage.len()
+
+	s.ctx.PushProxy(&MyStruct{Int: 21})
+	s.ctx.PushProxy(func() {})
+	c.Assert(s.ctx.storage.len(), Equals, count+2)
+
+	s.ctx.Pop2()
+	c.Assert(s.ctx.PevalString(`Duktape.gc()`), IsNil)
+	c.Assert(s.ctx.storage.len(), Equals, count)
+}
+
+func (s *CandySuite) TestPushProxy_FinalizeIdentity(c *C) {
+	m := &MyStruct{Int: 21}
+	s.ctx.PushProxy(m)
+	s.ctx.Pop()
+	c.Assert(s.ctx.PevalString(`Duktape.gc(); Duktape.gc()`), IsNil)
+	c.Assert(s.ctx.storage.getProxy(m) == nil, Equals, true)
+
+	s.ctx.PushGlobalProxy("test", m)
+	c.Assert(s.ctx.PevalString(`Duktape.gc(); Duktape.gc(); store(test.multiply(2))`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+}
+
+func (s *CandySuite) TestPushGlobalProxy_PointerIdentity(c *C) {
+	m := &MyStruct{Int: 21, Nested: &MyStruct{Int: 42}}
+	s.ctx.PushGlobalProxy("a", m)
+	s.ctx.PushGlobalProxy("b", m)
+	s.ctx.PushGlobalProxy("c", &MyStruct{Int: 21})
+	s.ctx.PushGlobalGoFunction("nested", func() *MyStruct { return m.Nested })
+
+	c.Assert(s.ctx.PevalString(`store([a === b, a === c, a.nested === nested(), nested() === nested()])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, false, true, true})
+}
+
 func (s *CandySuite) TestPushGlobalProxy_Integration(c *C) {
 	now := time.Now()
 	after := now.Add(time.Millisecond)
@@ -220,6 +352,39 @@ func (s *CandySuite) TestPushGlobalValueStruct(c *C) {
 	c.Assert(s.stored, Equals, 42.0)
 }
 
+func (s *CandySuite) TestPushGlobalValueStruct_Boxed(c *C) {
+	var received MyStruct
+	var receivedPtr *MyStruct
+	s.ctx.PushGlobalGoFunction("value", func() MyStruct { return MyStruct{Int: 21} })
+	s.ctx.PushGlobalGoFunction("receive", func(m MyStruct) { received = m })
+	s.ctx.PushGlobalGoFunction("receivePtr", func(m *MyStruct) { receivedPtr = m })
+
+	c.Assert(s.ctx.PevalString(`
+		var obj = value();
+		obj.int = 42;
+		receive(obj);
+		receivePtr(obj);
+		store(obj.multiply(2));
+	`), IsNil)
+	c.Assert(s.stored, Equals, 84.0)
+	c.Assert(received.Int, Equals, 42)
+	c.Assert(receivedPtr.Int, Equals, 42)
+
+	c.Assert(s.ctx.PevalString(`store(value())`), IsNil)
+	c.Assert(s.stored, DeepEquals, MyStruct{Int: 21})
+}
+
+func (s *CandySuite) TestPushGlobalValueStruct_ReadOnly(c *C) {
+	s.ctx.SetReadOnlyStructValues(true)
+	s.ctx.pushGlobalValue("test", reflect.ValueOf(MyStruct{Int: 42}))
+
+	c.Assert(s.ctx.PevalString(`test.int = 21; store(test.int)`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+
+	c.Assert(s.ctx.PevalString(`store("multiply" in test)`), IsNil)
+	c.Assert(s.stored, Equals, false)
+}
+
 func (s *CandySuite) TestPushGlobalValueStructPtr(c *C) {
 	s.ctx.pushGlobalValue("test", reflect.ValueOf(&MyStruct{Int: 42}))
 	c.Assert(s.ctx.PevalString(`store(test.int)`), IsNil)
@@ -400,6 +565,8 @@ func (s *CandySuite) TestPushGlobalGoFunction_Date(c *C) {
 }
 
 func (s *CandySuite) TestPushGlobalGoFunction_Optional(c *C) {
+	s.ctx.SetStrictArguments(false)
+
 	var cm, cs, ci, cst interface{}
 	s.ctx.PushGlobalGoFunction("test_optional", func(m map[string]interface{}, s []interface{}, i int, st string) {
 		cm = m
@@ -502,6 +669,16 @@ func (s *CandySuite) TestPushGlobalGoFunction_Error(c *C) {
 	c.Assert(s.stored, Equals, true)
 }
 
+func (s *CandySuite) TestPushGlobalProxy_ProxyProvider(c *C) {
+	s.ctx.PushGlobalProxy("test", &myProvidedStruct{First: "John", Last: "Doe"})
+
+	c.Assert(s.ctx.PevalString(`store([test.fullName, test.first, "last" in test])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"John Doe", "John", false})
+
+	c.Assert(s.ctx.PevalString(`test.first = "Jane"; store(test.fullName)`), IsNil)
+	c.Assert(s.stored, Equals, "Jane Doe")
+}
+
 func (s *CandySuite) TestJsonEncode(c *C) {
 	ms := &MyStruct{Date: time.Date(1984, 12, 24, 1, 2, 3, 456*int(time.Millisecond), time.UTC), Int: 142, Float64: 3.141596254}
 	s.ctx.PushGlobalProxy("test", ms)
@@ -772,6 +949,25 @@ func (p *myCustomProxy) Enumerate(t interface{}) (interface{}, error) {
 	return keys, nil
 }
 
+type myProvidedStruct struct {
+	First string
+	Last  string
+}
+
+func (m *myProvidedStruct) JSProxy() Proxy {
+	return &ProxyFuncs{
+		HasFunc: func(t interface{}, k string) bool {
+			return k == "fullName" || (k != "last" && p.Has(t, k))
+		},
+		GetFunc: func(t interface{}, k string, recv interface{}) (interface{}, error) {
+			if k == "fullName" {
+				return m.First + " " + m.Last, nil
+			}
+			return nil, ErrFallback
+		},
+	}
+}
+
 func (s *CandySuite) TestErrorFactory(c *C) {
 	s.ctx.SetErrorFactory(
 		func(ctx *Context, index int) error {
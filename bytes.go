@@ -0,0 +1,130 @@
+package candyjs
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/crazytyper/go-duktape"
+)
+
+// maxBufferSize is the size of the array used to alias the duktape buffers.
+const maxBufferSize = 1 << 30
+
+var typeBytes = reflect.TypeOf([]byte(nil))
+
+// SetZeroCopyBytes sets whether the []byte arguments of the Go functions called
+// from JS alias the memory of the JS buffers, instead of being copied. The
+// aliased slices are only valid during the call, they must not be retained or
+// appended to. The []byte values pushed to JS are always copied.
+func (ctx *Context) SetZeroCopyBytes(zeroCopy bool) {
+	ctx.zeroCopy = zeroCopy
+}
+
+func (ctx *Context) pushBytesHelpers() {
+	ctx.EvalStringNoresult(`(function() {
+		CandyJS._plainOf = function(v) {
+			if (v instanceof ArrayBuffer || ArrayBuffer.isView(v)) {
+				return Uint8Array.plainOf(v);
+			}
+		};
+
+		CandyJS.encode = function(str) {
+			return new TextEncoder().encode(String(str));
+		};
+
+		CandyJS.decode = function(buf, encoding) {
+			return new TextDecoder(encoding).decode(buf);
+		};
+	})()`)
+}
+
+// pushBytes pushes a copy of b as an Uint8Array.
+func (ctx *Context) pushBytes(b []byte) {
+	ptr := ctx.PushFixedBuffer(len(b))
+	if len(b) > 0 {
+		copy((*[maxBufferSize]byte)(ptr)[:len(b):len(b)], b)
+	}
+
+	ctx.PushBufferObject(-1, 0, len(b), uint(duktape.BufobjUint8Array))
+	ctx.Remove(-2)
+}
+
+// isBytesType returns true if the type t accepts a []byte.
+func isBytesType(t reflect.Type) bool {
+	return t == nil || t == typeInterface ||
+		(t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+// getBytesValue returns the bytes at the given index as a value of type t.
+func (ctx *Context) getBytesValue(index int, t reflect.Type, alias bool) (reflect.Value, bool) {
+	if !isBytesType(t) {
+		return reflect.Value{}, false
+	}
+
+	b, ok := ctx.getBytes(index, alias)
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	v := reflect.ValueOf(b)
+	if t != nil && t.Kind() == reflect.Slice {
+		v = v.Convert(t)
+	}
+
+	return v, true
+}
+
+// getBytes returns the bytes of the buffer, ArrayBuffer or typed array at the
+// given index, the bytes are copied unless alias is true.
+func (ctx *Context) getBytes(index int, alias bool) ([]byte, bool) {
+	index = ctx.NormalizeIndex(index)
+	if ctx.IsBuffer(index) {
+		ptr, size := ctx.GetBuffer(index)
+		return toBytes(ptr, 0, int(size), alias), true
+	}
+
+	if !ctx.IsObject(index) || ctx.IsFunction(index) {
+		return nil, false
+	}
+
+	ctx.GetPropString(index, "byteLength")
+	length := ctx.GetInt(-1)
+	isBuffer := ctx.IsNumber(-1)
+	ctx.Pop()
+	if !isBuffer {
+		return nil, false
+	}
+
+	ctx.GetPropString(index, "byteOffset")
+	offset := ctx.GetInt(-1)
+	ctx.Pop()
+
+	ctx.GetGlobalString("CandyJS")
+	ctx.PushString("_plainOf")
+	ctx.Dup(index)
+	defer ctx.Pop2()
+	if ctx.PcallProp(-3, 1) != 0 || !ctx.IsBuffer(-1) {
+		return nil, false
+	}
+
+	// the plain buffer is referenced by the buffer object at index, so it
+	// outlives the call.
+	ptr, _ := ctx.GetBuffer(-1)
+	return toBytes(ptr, offset, length, alias), true
+}
+
+func toBytes(ptr unsafe.Pointer, offset, length int, alias bool) []byte {
+	if length == 0 || ptr == nil {
+		return []byte{}
+	}
+
+	ptr = unsafe.Pointer(uintptr(ptr) + uintptr(offset))
+	if alias {
+		return (*[maxBufferSize]byte)(ptr)[:length:length]
+	}
+
+	return C.GoBytes(ptr, C.int(length))
+}
@@ -0,0 +1,84 @@
+package candyjs
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestPushBytes(c *C) {
+	s.ctx.PushGlobalInterface("b", []byte{0, 1, 255})
+	c.Assert(s.ctx.PevalString(`store([b instanceof Uint8Array, b.length, b[2]])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, 3.0, 255.0})
+}
+
+func (s *CandySuite) TestGetBytes(c *C) {
+	var received []byte
+	s.ctx.PushGlobalGoFunction("receive", func(b []byte) {
+		received = b
+	})
+
+	c.Assert(s.ctx.PevalString(`receive(new Uint8Array([0, 1, 255]))`), IsNil)
+	c.Assert(received, DeepEquals, []byte{0, 1, 255})
+
+	c.Assert(s.ctx.PevalString(`receive(new Uint8Array([0, 1, 2, 3]).subarray(1, 3))`), IsNil)
+	c.Assert(received, DeepEquals, []byte{1, 2})
+
+	c.Assert(s.ctx.PevalString(`receive(new Uint8Array([4, 5]).buffer)`), IsNil)
+	c.Assert(received, DeepEquals, []byte{4, 5})
+
+	c.Assert(s.ctx.PevalString(`receive(Uint8Array.plainOf(new Uint8Array([6])))`), IsNil)
+	c.Assert(received, DeepEquals, []byte{6})
+}
+
+func (s *CandySuite) TestGetBytesInterface(c *C) {
+	c.Assert(s.ctx.PevalString(`store(new Uint8Array([1, 2]))`), IsNil)
+	c.Assert(s.stored, DeepEquals, []byte{1, 2})
+}
+
+func (s *CandySuite) TestBytesRoundTrip(c *C) {
+	s.ctx.PushGlobalGoFunction("reverse", func(b []byte) []byte {
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return b
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		var b = new Uint8Array([0, 128, 255]);
+		var r = reverse(b);
+		store(b[0] === 0 && r instanceof Uint8Array ? r : null)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []byte{255, 128, 0})
+}
+
+func (s *CandySuite) TestBytesZeroCopy(c *C) {
+	s.ctx.SetZeroCopyBytes(true)
+	s.ctx.PushGlobalGoFunction("fill", func(b []byte) {
+		for i := range b {
+			b[i] = 42
+		}
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		var b = new Uint8Array(4);
+		fill(b.subarray(1, 3));
+		store(b)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []byte{0, 42, 42, 0})
+}
+
+func (s *CandySuite) TestBytesStructField(c *C) {
+	s.ctx.PushGlobalProxy("m", &MyStruct{Bytes: []byte("foo")})
+	c.Assert(s.ctx.PevalString(`store(CandyJS.decode(m.bytes))`), IsNil)
+	c.Assert(s.stored, Equals, "foo")
+
+	c.Assert(s.ctx.PevalString(`m.bytes = CandyJS.encode("bar"); store(m.bytes)`), IsNil)
+	c.Assert(s.stored, DeepEquals, []byte("bar"))
+}
+
+func (s *CandySuite) TestBytesEncodeDecode(c *C) {
+	c.Assert(s.ctx.PevalString(`
+		var b = CandyJS.encode("héllo");
+		store([b.length, CandyJS.decode(b)])
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{6.0, "héllo"})
+}
@@ -0,0 +1,177 @@
+package candyjs
+
+import "reflect"
+
+// chanKeys are the properties of the channel proxies.
+var chanKeys = []string{"send", "recv", "tryRecv", "next", "forEach", "close"}
+
+// chanProxy is the Proxy of the Go channels, exposing:
+//   - send(v): sends v, blocks until received if the channel is unbuffered
+//   - recv(): receives a value, returns null once the channel is closed
+//   - tryRecv(): receives without blocking, returns `{value, ok}`
+//   - next(): receives following the iterator protocol, returns `{value, done}`
+//   - forEach(fn): calls fn with every value until the channel is closed or
+//     fn returns false
+//   - close(): closes the channel
+//
+// The blocking operations are cancelled when the context.Context of the
+// evaluation is done.
+type chanProxy struct {
+	ctx *Context
+}
+
+func (p *chanProxy) Has(t interface{}, k string) bool {
+	for _, key := range chanKeys {
+		if key == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *chanProxy) Get(t interface{}, k string, recv interface{}) (interface{}, error) {
+	ch := reflect.ValueOf(t)
+	switch k {
+	case "send":
+		return func(v interface{}) error { return p.send(ch, v) }, nil
+	case "recv":
+		return func() (interface{}, error) {
+			v, _, err := p.recv(ch, true)
+			return v, err
+		}, nil
+	case "tryRecv":
+		return func() (map[string]interface{}, error) {
+			v, ok, err := p.recv(ch, false)
+			return map[string]interface{}{"value": v, "ok": ok}, err
+		}, nil
+	case "next":
+		return func() (map[string]interface{}, error) {
+			v, ok, err := p.recv(ch, true)
+			return map[string]interface{}{"value": v, "done": !ok}, err
+		}, nil
+	case "forEach":
+		return func(fn func(v interface{}) interface{}) error {
+			for {
+				v, ok, err := p.recv(ch, true)
+				if err != nil || !ok {
+					return err
+				}
+
+				if r, isBool := fn(v).(bool); isBool && !r {
+					return nil
+				}
+			}
+		}, nil
+	case "close":
+		return func() error { return p.close(ch) }, nil
+	case "toJSON", "valueOf", "":
+		return nil, nil
+	case "toString":
+		return func() string { return ch.Type().String() }, nil
+	}
+
+	return nil, errorf(ErrorCodeUndefinedProperty, "Undefined property %q on type %T", k, t)
+}
+
+func (p *chanProxy) Set(t interface{}, k string, v, recv interface{}) (bool, error) {
+	return false, nil
+}
+
+func (p *chanProxy) Enumerate(t interface{}) (interface{}, error) {
+	return chanKeys, nil
+}
+
+func (p *chanProxy) send(ch reflect.Value, v interface{}) (err error) {
+	if ch.Type().ChanDir()&reflect.SendDir == 0 {
+		return errorf(ErrorCodeChanDir, "Cannot send to receive-only channel %s", ch.Type())
+	}
+
+	value, err := Convert(ch.Type().Elem(), v)
+	if err != nil {
+		return err
+	}
+
+	send := reflect.Zero(ch.Type().Elem())
+	if value != nil {
+		send = reflect.ValueOf(value)
+	}
+
+	defer func() {
+		if isPanic(recover(), "send on closed channel") {
+			err = errorf(ErrorCodeChanClosed, "Send on closed channel %s", ch.Type())
+		}
+	}()
+
+	done := p.ctx.goContext().Done()
+	chosen, _, _ := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: ch, Send: send},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(done)},
+	})
+	if chosen == 1 {
+		return p.ctx.goContext().Err()
+	}
+
+	return nil
+}
+
+func (p *chanProxy) recv(ch reflect.Value, block bool) (interface{}, bool, error) {
+	if ch.Type().ChanDir()&reflect.RecvDir == 0 {
+		return nil, false, errorf(ErrorCodeChanDir, "Cannot receive from send-only channel %s", ch.Type())
+	}
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(p.ctx.goContext().Done())},
+	}
+	if !block {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	}
+
+	switch chosen, v, ok := reflect.Select(cases); chosen {
+	case 0:
+		if !ok {
+			return nil, false, nil
+		}
+		return v.Interface(), true, nil
+	case 1:
+		return nil, false, p.ctx.goContext().Err()
+	}
+
+	return nil, false, nil
+}
+
+func (p *chanProxy) close(ch reflect.Value) (err error) {
+	if ch.Type().ChanDir()&reflect.SendDir == 0 {
+		return errorf(ErrorCodeChanDir, "Cannot close receive-only channel %s", ch.Type())
+	}
+
+	defer func() {
+		if isPanic(recover(), "close of closed channel") {
+			err = errorf(ErrorCodeChanClosed, "Close of closed channel %s", ch.Type())
+		}
+	}()
+
+	ch.Close()
+	return nil
+}
+
+// isPanic returns true if the recovered value r is the runtime panic with the
+// given message, any other panic is raised again.
+func isPanic(r interface{}, msg string) bool {
+	if r == nil {
+		return false
+	}
+
+	if err, ok := r.(error); ok && err.Error() == msg {
+		return true
+	}
+
+	panic(r)
+}
+
+// makeChan backs `CandyJS.makeChan(size)`, the channels created in JS can be
+// passed to Go functions expecting a `chan interface{}`.
+func makeChan(size int) chan interface{} {
+	return make(chan interface{}, size)
+}
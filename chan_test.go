@@ -0,0 +1,139 @@
+package candyjs
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestChanRecv(c *C) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	close(ch)
+
+	s.ctx.PushGlobalProxy("ch", ch)
+	c.Assert(s.ctx.PevalString(`store([ch.recv(), ch.recv(), ch.recv()])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{1.0, 2.0, nil})
+}
+
+func (s *CandySuite) TestChanSend(c *C) {
+	ch := make(chan int, 2)
+	s.ctx.PushGlobalGoFunction("getChan", func() chan int { return ch })
+
+	c.Assert(s.ctx.PevalString(`var ch = getChan(); ch.send(21); ch.send(42); ch.close()`), IsNil)
+	c.Assert(<-ch, Equals, 21)
+	c.Assert(<-ch, Equals, 42)
+
+	_, ok := <-ch
+	c.Assert(ok, Equals, false)
+}
+
+func (s *CandySuite) TestChanTryRecv(c *C) {
+	ch := make(chan string, 1)
+	s.ctx.PushGlobalProxy("ch", ch)
+
+	c.Assert(s.ctx.PevalString(`store(ch.tryRecv().ok)`), IsNil)
+	c.Assert(s.stored, Equals, false)
+
+	ch <- "foo"
+	c.Assert(s.ctx.PevalString(`store(ch.tryRecv())`), IsNil)
+	c.Assert(s.stored, DeepEquals, map[string]interface{}{"value": "foo", "ok": true})
+}
+
+func (s *CandySuite) TestChanForEach(c *C) {
+	ch := make(chan int)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+
+	s.ctx.PushGlobalProxy("ch", ch)
+	c.Assert(s.ctx.PevalString(`
+		var values = [];
+		ch.forEach(function(v) { values.push(v) });
+		store(values)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{1.0, 2.0, 3.0})
+}
+
+func (s *CandySuite) TestChanNext(c *C) {
+	ch := make(chan int, 1)
+	ch <- 42
+	close(ch)
+
+	s.ctx.PushGlobalProxy("ch", ch)
+	c.Assert(s.ctx.PevalString(`
+		var values = [];
+		for (var r = ch.next(); !r.done; r = ch.next()) values.push(r.value);
+		store(values)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{42.0})
+}
+
+func (s *CandySuite) TestChanDirection(c *C) {
+	ch := make(chan int)
+	s.ctx.PushGlobalProxy("ch", (<-chan int)(ch))
+
+	c.Assert(s.ctx.PevalString(`ch.send(1)`), ErrorMatches, ".*Cannot send to receive-only channel.*")
+	c.Assert(s.ctx.GoErrors(), HasLen, 1)
+}
+
+func (s *CandySuite) TestChanClosed(c *C) {
+	ch := make(chan int)
+	close(ch)
+	s.ctx.PushGlobalProxy("ch", ch)
+
+	c.Assert(s.ctx.PevalString(`ch.send(1)`), ErrorMatches, ".*Send on closed channel.*")
+	c.Assert(s.ctx.GoErrors(), HasLen, 1)
+}
+
+func (s *CandySuite) TestChanCancel(c *C) {
+	s.ctx.PushGlobalProxy("ch", make(chan int))
+
+	goCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	c.Assert(s.ctx.PevalStringContext(goCtx, `ch.recv()`), Equals, context.DeadlineExceeded)
+	c.Assert(s.ctx.GoErrors(), DeepEquals, []error{context.DeadlineExceeded})
+}
+
+func (s *CandySuite) TestChanMakeChan(c *C) {
+	var received []interface{}
+	s.ctx.PushGlobalGoFunction("consume", func(ch chan interface{}) {
+		for v := range ch {
+			received = append(received, v)
+		}
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		var ch = CandyJS.makeChan(2);
+		ch.send("foo"); ch.send(42); ch.close();
+		consume(ch)
+	`), IsNil)
+	c.Assert(received, DeepEquals, []interface{}{"foo", 42.0})
+}
+
+func (s *CandySuite) TestChanSendNull(c *C) {
+	ptrs := make(chan *MyStruct, 1)
+	values := make(chan interface{}, 1)
+	s.ctx.PushGlobalProxy("ptrs", ptrs)
+	s.ctx.PushGlobalProxy("values", values)
+
+	c.Assert(s.ctx.PevalString(`ptrs.send(null); values.send(undefined)`), IsNil)
+	c.Assert(<-ptrs, IsNil)
+	c.Assert(<-values, IsNil)
+}
+
+func (s *CandySuite) TestChanSendProxy(c *C) {
+	value := &MyStruct{Int: 42}
+	ch := make(chan interface{}, 1)
+	s.ctx.PushGlobalProxy("ch", ch)
+	s.ctx.PushGlobalProxy("value", value)
+
+	c.Assert(s.ctx.PevalString(`ch.send(value)`), IsNil)
+	c.Assert(<-ch, Equals, value)
+}
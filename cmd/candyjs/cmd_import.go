@@ -18,9 +18,10 @@ import (
 // CmdImport generates a new candyjs.PackagePusher function for the given
 // Package, the package can be any builtin or any other third party one.
 type CmdImport struct {
-	Output string `short:"" long:"output" description:"output file name" default:"pkg_%s.go"`
-	Debug  bool   `short:"" long:"debug" description:"active debug messages"`
-	Args   struct {
+	Output       string `short:"" long:"output" description:"output file name" default:"pkg_%s.go"`
+	Debug        bool   `short:"" long:"debug" description:"active debug messages"`
+	NamedResults bool   `short:"" long:"named-results" description:"return the named results of the functions as objects"`
+	Args         struct {
 		Package string `positional-arg-name:"package" description:"package to import"`
 	} `positional-args:"yes" required:"true"`
 
@@ -108,22 +109,35 @@ func (c *CmdImport) getPackagePath(pkgName string) (string, error) {
 		}
 	}
 
-	return "", errorf(ErrorCodePackageNotFound, "package %q not found", pkgName)
+	return "", fmt.Errorf("package %q not found", pkgName)
 }
 
 func (c *CmdImport) render(objs map[string]*ast.Object) error {
+	output, err := c.renderSource(objs)
+	if err != nil {
+		return err
+	}
+
+	file := fmt.Sprintf(c.Output, c.pkgName)
+	fmt.Printf("File generated %q\n", file)
+
+	return ioutil.WriteFile(file, output, 0644)
+}
+
+func (c *CmdImport) renderSource(objs map[string]*ast.Object) ([]byte, error) {
 	t := template.New("tmpl")
 	t.Funcs(template.FuncMap{
 		"isFunc":           isFunc,
 		"isVar":            isVar,
 		"isConst":          isConst,
 		"isStruct":         isStruct,
+		"resultNames":      c.resultNames,
 		"nameToJavaScript": nameToJavaScript,
 	})
 
 	_, err := t.Parse(formatTemplateNewLines(tmpl))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	buf := bytes.NewBuffer(nil)
@@ -137,18 +151,10 @@ func (c *CmdImport) render(objs map[string]*ast.Object) error {
 	})
 
 	if err != nil {
-		return err
-	}
-
-	output, err := format.Source(buf.Bytes())
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	file := fmt.Sprintf(c.Output, c.pkgName)
-	fmt.Printf("File generated %q\n", file)
-
-	return ioutil.WriteFile(file, output, 0644)
+	return format.Source(buf.Bytes())
 }
 
 func formatTemplateNewLines(tmpl string) string {
@@ -167,6 +173,41 @@ func isConst(obj *ast.Object) bool {
 	return obj.Kind == ast.Con
 }
 
+// resultNames returns the names of the results of the function, the errors
+// excluded, as a list of Go strings, or "" if the function has less than two
+// named results or the named results are not enabled.
+func (c *CmdImport) resultNames(obj *ast.Object) string {
+	decl, ok := obj.Decl.(*ast.FuncDecl)
+	if !c.NamedResults || !ok || decl.Type.Results == nil {
+		return ""
+	}
+
+	var names []string
+	for _, field := range decl.Type.Results.List {
+		if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "error" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			return ""
+		}
+
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				return ""
+			}
+
+			names = append(names, fmt.Sprintf("%q", name.Name))
+		}
+	}
+
+	if len(names) < 2 {
+		return ""
+	}
+
+	return strings.Join(names, ", ")
+}
+
 func isStruct(obj *ast.Object) bool {
 	if obj.Kind != ast.Typ {
 		return false
@@ -205,7 +246,7 @@ package {{.CurPkgName}}
 import (
 	"{{$fullPkg}}"
 
-	"github.com/mcuadros/go-candyjs"
+	"github.com/crazytyper/go-candyjs"
 )
 
 func init() {
@@ -213,7 +254,12 @@ func init() {
 		ctx.PushObject()
 		{{range .Objs}} \
 		{{if isFunc .}} \
-			ctx.PushGoFunction({{$pkg}}.{{.Name}})
+		{{$name := .Name}} \
+		{{with resultNames .}} \
+			ctx.PushGoFunction(candyjs.Func{Fn: {{$pkg}}.{{$name}}, Results: []string{ {{.}} }})
+		{{else}} \
+			ctx.PushGoFunction({{$pkg}}.{{$name}})
+		{{end}} \
 			ctx.PutPropString(-2, "{{nameToJavaScript .Name}}")
 		{{else if isStruct .}} \
 			ctx.PushType({{$pkg}}.{{.Name}}{})
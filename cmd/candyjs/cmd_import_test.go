@@ -0,0 +1,71 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const testPackage = `package geo
+
+type Point struct{ X, Y float64 }
+
+var Origin = Point{}
+
+const Zero = 0
+
+func Distance(a, b Point) float64 { return 0 }
+
+func Split(p Point) (x, y float64, err error) { return p.X, p.Y, nil }
+`
+
+func renderTestPackage(t *testing.T, c *CmdImport) string {
+	f, err := parser.ParseFile(token.NewFileSet(), "geo.go", testPackage, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs := make(map[string]*ast.Object)
+	for name, obj := range f.Scope.Objects {
+		objs[name] = obj
+	}
+
+	c.fullPkgName, c.curPkgName, c.pkgName = "example.com/geo", "main", "geo"
+	output, err := c.renderSource(objs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(output)
+}
+
+func TestCmdImportRender(t *testing.T) {
+	output := renderTestPackage(t, &CmdImport{})
+	for _, line := range []string{
+		`"github.com/crazytyper/go-candyjs"`,
+		`ctx.PushGoFunction(geo.Distance)`,
+		`ctx.PushGoFunction(geo.Split)`,
+		`ctx.PutPropString(-2, "split")`,
+		`ctx.PushType(geo.Point{})`,
+		`ctx.PushProxy(geo.Origin)`,
+		`ctx.PushInterface(geo.Zero)`,
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("missing %s in:\n%s", line, output)
+		}
+	}
+}
+
+func TestCmdImportRenderNamedResults(t *testing.T) {
+	output := renderTestPackage(t, &CmdImport{NamedResults: true})
+	for _, line := range []string{
+		`ctx.PushGoFunction(geo.Distance)`,
+		`ctx.PushGoFunction(candyjs.Func{Fn: geo.Split, Results: []string{"x", "y"}})`,
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("missing %s in:\n%s", line, output)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package candyjs
+
+import (
+	"unicode/utf8"
+
+	"github.com/crazytyper/go-cesu8"
+)
+
+// Duktape represents the strings internally as CESU-8, where the characters
+// outside the BMP are encoded as surrogate pairs. Every Go string crossing the
+// boundary goes through the methods below, the strings coming from duktape
+// are already decoded by go-duktape.
+
+// PushString pushes the given Go string, encoded as CESU-8.
+func (ctx *Context) PushString(str string) string {
+	return ctx.Context.PushString(encodeString(str))
+}
+
+// GetPropString like duktape.Context.GetPropString with CESU-8 encoded keys.
+func (ctx *Context) GetPropString(objIndex int, key string) bool {
+	return ctx.Context.GetPropString(objIndex, encodeString(key))
+}
+
+// PutPropString like duktape.Context.PutPropString with CESU-8 encoded keys.
+func (ctx *Context) PutPropString(objIndex int, key string) bool {
+	return ctx.Context.PutPropString(objIndex, encodeString(key))
+}
+
+// HasPropString like duktape.Context.HasPropString with CESU-8 encoded keys.
+func (ctx *Context) HasPropString(objIndex int, key string) bool {
+	return ctx.Context.HasPropString(objIndex, encodeString(key))
+}
+
+// DelPropString like duktape.Context.DelPropString with CESU-8 encoded keys.
+func (ctx *Context) DelPropString(objIndex int, key string) bool {
+	return ctx.Context.DelPropString(objIndex, encodeString(key))
+}
+
+// GetGlobalString like duktape.Context.GetGlobalString with CESU-8 encoded
+// keys.
+func (ctx *Context) GetGlobalString(key string) bool {
+	return ctx.Context.GetGlobalString(encodeString(key))
+}
+
+// PutGlobalString like duktape.Context.PutGlobalString with CESU-8 encoded
+// keys.
+func (ctx *Context) PutGlobalString(key string) bool {
+	return ctx.Context.PutGlobalString(encodeString(key))
+}
+
+// encodeString encodes the characters outside the BMP of s as CESU-8, any
+// other byte, including the invalid UTF-8 used by the hidden properties, is
+// kept as is.
+func encodeString(s string) string {
+	i := 0
+	for ; i < len(s); i++ {
+		if isFourByteStart(s[i]) {
+			break
+		}
+	}
+
+	if i == len(s) {
+		return s
+	}
+
+	var p [6]byte
+	buf := make([]byte, i, len(s)+len(s)/2)
+	copy(buf, s[:i])
+	for i < len(s) {
+		if isFourByteStart(s[i]) {
+			if r, size := utf8.DecodeRuneInString(s[i:]); size == 4 {
+				n := cesu8.EncodeRune(p[:], r)
+				buf = append(buf, p[:n]...)
+				i += size
+				continue
+			}
+		}
+
+		buf = append(buf, s[i])
+		i++
+	}
+
+	return string(buf)
+}
+
+func isFourByteStart(b byte) bool {
+	return b >= 0xF0 && b <= 0xF4
+}
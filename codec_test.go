@@ -0,0 +1,97 @@
+package candyjs
+
+import (
+	"errors"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+const astral = "😀𝄞𠜎"
+
+func (s *CandySuite) TestEncodeString(c *C) {
+	c.Assert(encodeString("foo"), Equals, "foo")
+	c.Assert(encodeString(goProxyPtrProp), Equals, goProxyPtrProp)
+	c.Assert(encodeString("é"+astral), Not(Equals), "é"+astral)
+	c.Assert(encodeString(encodeString(astral)), Equals, encodeString(astral))
+}
+
+func (s *CandySuite) TestCESU8Values(c *C) {
+	s.ctx.PushGlobalGoFunction("echo", func(s string) string { return s + astral })
+	c.Assert(s.ctx.PevalString(`store([echo("`+astral+`"), "`+astral+`".length])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{astral + astral, 6.0})
+}
+
+func (s *CandySuite) TestCESU8GlobalNames(c *C) {
+	s.ctx.PushGlobalInterface(astral, astral)
+	c.Assert(s.ctx.PevalString(`store(this["`+astral+`"])`), IsNil)
+	c.Assert(s.stored, Equals, astral)
+}
+
+func (s *CandySuite) TestCESU8ProxyKeys(c *C) {
+	p := &myCustomProxy{values: map[string]interface{}{astral: astral}}
+	s.ctx.PushGlobalProxy("p", p)
+
+	c.Assert(s.ctx.PevalString(`store(p["`+astral+`"])`), IsNil)
+	c.Assert(s.stored, Equals, astral)
+
+	c.Assert(s.ctx.PevalString(`p["`+astral+`!"] = "`+astral+`"`), IsNil)
+	c.Assert(p.values[astral+"!"], Equals, astral)
+
+	c.Assert(s.ctx.PevalString(`store("`+astral+`" in p)`), IsNil)
+	c.Assert(s.stored, Equals, true)
+	c.Assert(p.calls[len(p.calls)-1], Equals, "has("+astral+")")
+
+	c.Assert(s.ctx.PevalString(`store(Object.getOwnPropertyNames(p).sort())`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{astral, astral + "!"})
+}
+
+func (s *CandySuite) TestCESU8MapKeys(c *C) {
+	s.ctx.PushGlobalInterface("m", map[string]string{astral: astral})
+	c.Assert(s.ctx.PevalString(`store([Object.keys(m)[0], m["`+astral+`"]])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{astral, astral})
+
+	var m map[string]string
+	c.Assert(s.ctx.PevalString(`({"`+astral+`": "`+astral+`"})`), IsNil)
+	c.Assert(s.ctx.GetValue(-1, &m), IsNil)
+	c.Assert(m, DeepEquals, map[string]string{astral: astral})
+}
+
+func (s *CandySuite) TestCESU8StructFields(c *C) {
+	s.ctx.PushGlobalProxy("m", &MyStruct{String: astral})
+	c.Assert(s.ctx.PevalString(`store(m.string); m.string += "!"`), IsNil)
+	c.Assert(s.stored, Equals, astral)
+
+	c.Assert(s.ctx.PevalString(`store(JSON.stringify(m.string))`), IsNil)
+	c.Assert(s.stored, Equals, `"`+astral+`!"`)
+}
+
+func (s *CandySuite) TestCESU8Errors(c *C) {
+	s.ctx.PushGlobalGoFunction("call", func(f func() error) error { return f() })
+	c.Assert(s.ctx.PevalString(`call(function() { throw new Error("`+astral+`") })`), ErrorMatches, "Error: "+astral)
+	c.Assert(s.ctx.GoErrors(), HasLen, 1)
+	c.Assert(s.ctx.GoErrors()[0], ErrorMatches, "Error: "+astral)
+
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errors.New(astral) })
+	c.Assert(s.ctx.PevalString(`fail()`), ErrorMatches, astral)
+	c.Assert(s.ctx.GoErrors(), HasLen, 1)
+	c.Assert(s.ctx.GoErrors()[0], ErrorMatches, astral)
+
+	err := s.ctx.PevalString(`throw new Error("` + astral + `")`)
+	c.Assert(err, ErrorMatches, "Error: "+astral)
+}
+
+func (s *CandySuite) TestCESU8Bytes(c *C) {
+	s.ctx.PushGlobalInterface("b", []byte(astral))
+	c.Assert(s.ctx.PevalString(`store(CandyJS.decode(b))`), IsNil)
+	c.Assert(s.stored, Equals, astral)
+
+	c.Assert(s.ctx.PevalString(`store(CandyJS.encode("`+astral+`"))`), IsNil)
+	c.Assert(s.stored, DeepEquals, []byte(astral))
+}
+
+func (s *CandySuite) TestCESU8Streams(c *C) {
+	s.ctx.PushGlobalProxy("r", strings.NewReader(astral+"\n"+astral))
+	c.Assert(s.ctx.PevalString(`store([r.readLine(), r.readAll()])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{astral, astral})
+}
@@ -0,0 +1,33 @@
+package candyjs
+
+import "context"
+
+// PevalStringContext is like PevalString, the blocking operations done during
+// the evaluation are cancelled when c is done.
+func (ctx *Context) PevalStringContext(c context.Context, src string) error {
+	defer ctx.setGoContext(c)()
+	return ctx.PevalString(src)
+}
+
+// PevalFileContext is like PevalFile, the blocking operations done during
+// the evaluation are cancelled when c is done.
+func (ctx *Context) PevalFileContext(c context.Context, path string) error {
+	defer ctx.setGoContext(c)()
+	return ctx.PevalFile(path)
+}
+
+// setGoContext sets the context.Context of the evaluation, returns a function
+// restoring the previous one.
+func (ctx *Context) setGoContext(c context.Context) func() {
+	prev := ctx.goCtx
+	ctx.goCtx = c
+	return func() { ctx.goCtx = prev }
+}
+
+func (ctx *Context) goContext() context.Context {
+	if ctx.goCtx == nil {
+		return context.Background()
+	}
+
+	return ctx.goCtx
+}
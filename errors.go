@@ -9,6 +9,26 @@ const (
 	// happend when a PackagePusher function was not registered using
 	// RegisterPackagePusher.
 	ErrorCodePackageNotFound = "candyjs:packagenotfound"
+	// ErrorCodeNotCallable is returned when calling a proxied value that is not
+	// a function.
+	ErrorCodeNotCallable = "candyjs:notcallable"
+	// ErrorCodeChanDir is returned when sending to a receive-only channel or
+	// receiving from a send-only channel.
+	ErrorCodeChanDir = "candyjs:chandir"
+	// ErrorCodeChanClosed is returned when sending to or closing a closed
+	// channel.
+	ErrorCodeChanClosed = "candyjs:chanclosed"
+	// ErrorCodeInvalidTime is returned when a value cannot be decoded into a
+	// time.Time or a time.Duration.
+	ErrorCodeInvalidTime = "candyjs:invalidtime"
+	// ErrorCodeJSError is returned by the JSError values, the exceptions
+	// thrown in JS.
+	ErrorCodeJSError = "candyjs:jserror"
+	// ErrorCodePanic is returned when a Go function called from JS panics.
+	ErrorCodePanic = "candyjs:panic"
+	// ErrorCodeTypeError is returned when a Go function is called with invalid
+	// arguments, it is thrown as a TypeError.
+	ErrorCodeTypeError = "candyjs:typeerror"
 )
 
 // Error represents an error returned by candy JS
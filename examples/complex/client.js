@@ -1,10 +1,9 @@
 var http = CandyJS.require('net/http');
-var ioutil = CandyJS.require('io/ioutil');
 
 resp = http.get('http://localhost:8080/back');
 
 if (resp.statusCode == 200) {
-  var json = ioutil.readAll(resp.body);
+  var json = resp.body.readAll();
   var obj = JSON.parse(json);
 
   print('Back to the future date:', obj.future);
@@ -9,7 +9,6 @@ import (
 
 //go:generate candyjs import time
 //go:generate candyjs import net/http
-//go:generate candyjs import io/ioutil
 //go:generate candyjs import github.com/gin-gonic/gin
 func main() {
 	script := os.Args[1]
@@ -18,7 +18,7 @@ func main() {
             writeString(writer, "Hello from CandyJS!")
         }
 
-        handleFunc("/", CandyJS.proxy(handler))
+        handleFunc("/", handler)
         listenAndServe(":8000", null)
     `)
 }
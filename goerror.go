@@ -0,0 +1,237 @@
+package candyjs
+
+import (
+	"fmt"
+
+	"github.com/crazytyper/go-duktape"
+)
+
+const (
+	goErrorProp     = "\xff" + "goErrorProp"
+	heapStashWrap   = "wrap"
+	heapStashThrown = "thrown"
+	thrownErrorProp = "error"
+)
+
+// pushWrapHelpers stores into the heap stash the function wrapping the Go
+// functions and the value they return to throw an error, see pushNative.
+func (ctx *Context) pushWrapHelpers() {
+	ctx.PushHeapStash()
+	ctx.EvalString(`(function() {
+		"use strict";
+		var thrown = {};
+		var wrap = function(fn) {
+			var wrapped = function() {
+				var ret = fn.apply(this, arguments);
+				if (ret === thrown) {
+					var err = thrown.error;
+					thrown.error = undefined;
+					throw err;
+				}
+
+				return ret;
+			};
+
+			// the wrapped functions print as the native ones
+			Object.defineProperty(wrapped, "toString", {value: function() {
+				return Function.prototype.toString.call(fn);
+			}});
+
+			return wrapped;
+		};
+
+		return [wrap, thrown];
+	})()`)
+	ctx.GetPropIndex(-1, 0)
+	ctx.PutPropString(-3, heapStashWrap)
+	ctx.GetPropIndex(-1, 1)
+	ctx.PutPropString(-3, heapStashThrown)
+	ctx.Pop2()
+}
+
+// pushNative pushes fn as a JS function. Duktape must not unwind the Go
+// frames, so fn is called by a JS function throwing the errors of
+// throwGoError once fn returned.
+func (ctx *Context) pushNative(fn func(*duktape.Context) int) int {
+	ctx.PushHeapStash()
+	ctx.GetPropString(-1, heapStashWrap)
+	ctx.Remove(-2)
+	ctx.Context.PushGoFunction(func(c *duktape.Context) int {
+		if ctx.evalDepth > 0 {
+			return fn(c)
+		}
+
+		// called by JS run without enterJS, e.g. by duktape.Context.Pcall
+		var rc int
+		ctx.enterJS(func() { rc = fn(c) })
+		return rc
+	})
+	ctx.Call(1)
+
+	return ctx.NormalizeIndex(-1)
+}
+
+// pushGlobalNative like pushNative but pushed to the global object, the name
+// is validated by duktape.Context.PushGlobalGoFunction.
+func (ctx *Context) pushGlobalNative(name string, fn func(*duktape.Context) int) (int, error) {
+	idx, err := ctx.Context.PushGlobalGoFunction(name, fn)
+	if err != nil {
+		return idx, err
+	}
+
+	ctx.PushGlobalObject()
+	ctx.pushNative(fn)
+	ctx.PutPropString(-2, name)
+	ctx.Pop()
+
+	return idx, nil
+}
+
+// maxGoErrors is the number of errors kept by GoErrors.
+const maxGoErrors = 64
+
+// GoErrors returns the errors returned by, or recovered from, the Go functions
+// called from JS during the last call from Go into JS, in the order they were
+// raised, up to the last 64. These calls are the evaluations, the calls of the
+// JS functions passed to Go and, for the JS run by the duktape.Context, the
+// calls of the Go functions. Every error is also attached to the JS error
+// thrown for it, the nested calls don't overwrite the errors of the outer ones.
+func (ctx *Context) GoErrors() []error {
+	return ctx.goErrors
+}
+
+// throwGoError pushes the JS error for err, see pushGoError, and returns the
+// duktape return code of the native functions throwing it, see pushNative.
+func (ctx *Context) throwGoError(err error) int {
+	if len(ctx.goErrors) == maxGoErrors {
+		ctx.goErrors = append([]error(nil), ctx.goErrors[1:]...)
+	}
+
+	ctx.goErrors = append(ctx.goErrors, err)
+	ctx.lastGoError = err
+	ctx.pushGoError(err)
+	ctx.pushThrown()
+	ctx.Swap(-1, -2)
+	ctx.PutPropString(-2, thrownErrorProp)
+
+	return 1
+}
+
+// isThrownGoError returns true if the value at the given index is the value
+// returned by throwGoError.
+func (ctx *Context) isThrownGoError(index int) bool {
+	index = ctx.NormalizeIndex(index)
+	ctx.pushThrown()
+	defer ctx.Pop()
+
+	return ctx.StrictEquals(index, -1)
+}
+
+// pushThrown pushes the value returned by the Go functions throwing an error.
+func (ctx *Context) pushThrown() {
+	ctx.PushHeapStash()
+	ctx.GetPropString(-1, heapStashThrown)
+	ctx.Remove(-2)
+}
+
+// pushGoError pushes a JS error for err, with the `message`, `code` and
+// `goType` of err, a TypeError for the errors with the ErrorCodeTypeError.
+func (ctx *Context) pushGoError(err error) {
+	code := duktape.ErrError
+	if ErrorCode(err) == ErrorCodeTypeError {
+		code = duktape.ErrType
+	}
+
+	// the message is set as a property, PushErrorObject formats it with C
+	ctx.PushErrorObject(code, "%s", "")
+	obj := ctx.NormalizeIndex(-1)
+	ctx.PushString(err.Error())
+	ctx.PutPropString(obj, "message")
+	if code := ErrorCode(err); code != "" {
+		ctx.PushString(code)
+		ctx.PutPropString(obj, "code")
+	}
+
+	ctx.PushString(fmt.Sprintf("%T", err))
+	ctx.PutPropString(obj, "goType")
+
+	if p, ok := err.(*PanicError); ok {
+		ctx.PushString(p.Stack)
+		ctx.PutPropString(obj, "goStack")
+	}
+
+	ctx.PushPointer(ctx.storage.add(err))
+	ctx.PutPropString(obj, goErrorProp)
+	ctx.setProxyFinalizer(obj)
+}
+
+// getGoError returns the Go error thrown as the JS error at the given index,
+// or nil if it was not thrown by a Go function.
+func (ctx *Context) getGoError(index int) error {
+	if !ctx.IsObject(index) {
+		return nil
+	}
+
+	defer ctx.Pop()
+	if !ctx.GetPropString(index, goErrorProp) || !ctx.IsPointer(-1) {
+		return nil
+	}
+
+	err, _ := ctx.storage.get(ctx.GetPointer(-1)).(error)
+	return err
+}
+
+// evaluate runs eval, see enterJS.
+func (ctx *Context) evaluate(eval func() error) error {
+	var err error
+	ctx.enterJS(func() { err = eval() })
+	return ctx.evalError(err)
+}
+
+// enterJS runs call, a call from Go into JS, the Go errors are reset by the
+// outermost one. The values of the collected jsRef are unpinned first.
+func (ctx *Context) enterJS(call func()) {
+	ctx.releaseRefs()
+	if ctx.evalDepth == 0 {
+		ctx.goErrors = nil
+	}
+
+	ctx.evalDepth++
+	defer func() { ctx.evalDepth-- }()
+
+	call()
+}
+
+// evalError returns the error thrown by the evaluation that returned err,
+// following the rules of getError, the JS error is left on the stack.
+func (ctx *Context) evalError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return ctx.getError(-1)
+}
+
+// Peval like duktape.Context.Peval, the errors returned by Go functions are
+// returned as is and the JS exceptions as a *JSError.
+func (ctx *Context) Peval() error {
+	return ctx.evaluate(ctx.Context.Peval)
+}
+
+// PevalString like duktape.Context.PevalString, the errors returned by Go
+// functions are returned as is and the JS exceptions as a *JSError.
+func (ctx *Context) PevalString(src string) error {
+	return ctx.evaluate(func() error { return ctx.Context.PevalString(src) })
+}
+
+// PevalLstring like duktape.Context.PevalLstring, the errors returned by Go
+// functions are returned as is and the JS exceptions as a *JSError.
+func (ctx *Context) PevalLstring(src string, length int) error {
+	return ctx.evaluate(func() error { return ctx.Context.PevalLstring(src, length) })
+}
+
+// PevalFile like duktape.Context.PevalFile, the errors returned by Go
+// functions are returned as is and the JS exceptions as a *JSError.
+func (ctx *Context) PevalFile(path string) error {
+	return ctx.evaluate(func() error { return ctx.Context.PevalFile(path) })
+}
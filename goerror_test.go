@@ -0,0 +1,226 @@
+package candyjs
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+var errGoSentinel = errors.New("sentinel")
+
+type goTestError struct {
+	Field string
+}
+
+func (e *goTestError) Error() string {
+	return "invalid " + e.Field
+}
+
+func (s *CandySuite) TestGoErrorProperties(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error {
+		return errorf(ErrorCodeUndefinedProperty, "foo is undefined")
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		try {
+			fail()
+		} catch (e) {
+			store([e instanceof Error, e.message, e.code, e.goType])
+		}
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		true, "foo is undefined", ErrorCodeUndefinedProperty, "*candyjs.candyError",
+	})
+}
+
+func (s *CandySuite) TestGoErrorWithoutCode(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+
+	c.Assert(s.ctx.PevalString(`
+		try { fail() } catch (e) { store([e.message, e.code, String(e)]) }
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"sentinel", nil, "Error: sentinel"})
+}
+
+func (s *CandySuite) TestGoErrorNoHook(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+
+	c.Assert(s.ctx.PevalString(`
+		var hooked = typeof Duktape.errCreate;
+		Duktape.errCreate = function(e) { e.created = true; return e };
+		try { fail() } catch (e) { store([hooked, e.message, e.goType, e.created]) }
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"undefined", "sentinel", "*errors.errorString", true})
+}
+
+func (s *CandySuite) TestGoErrorPeval(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+
+	err := s.ctx.PevalString(`fail()`)
+	c.Assert(err == errGoSentinel, Equals, true)
+	c.Assert(errors.Is(err, errGoSentinel), Equals, true)
+}
+
+func (s *CandySuite) TestGoErrorAs(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func(field string) (int, error) {
+		return 0, &goTestError{Field: field}
+	})
+
+	err := s.ctx.PevalString(`
+		try {
+			fail("foo")
+		} catch (e) {
+			throw e // rethrown as is
+		}
+	`)
+
+	var target *goTestError
+	c.Assert(errors.As(err, &target), Equals, true)
+	c.Assert(target.Field, Equals, "foo")
+}
+
+func (s *CandySuite) TestGoErrorCallback(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+	s.ctx.PushGlobalGoFunction("call", func(f func() error) error { return f() })
+
+	c.Assert(s.ctx.PevalString(`call(function() { fail() })`), Equals, errGoSentinel)
+}
+
+func (s *CandySuite) TestGoErrorJSErrors(c *C) {
+	err := s.ctx.PevalString(`throw new Error("foo")`)
+	c.Assert(err, FitsTypeOf, &JSError{})
+	c.Assert(err, ErrorMatches, "Error: foo")
+	c.Assert(s.ctx.GoErrors(), HasLen, 0)
+
+	err = s.ctx.PevalString(`undefinedFunction()`)
+	c.Assert(err, FitsTypeOf, &JSError{})
+	c.Assert(err.(*JSError).Name, Equals, "ReferenceError")
+}
+
+func (s *CandySuite) TestGoErrorsNested(c *C) {
+	errOuter := errors.New("outer")
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+	s.ctx.PushGlobalGoFunction("call", func(f func() error) error {
+		if err := f(); err != errGoSentinel {
+			return err
+		}
+
+		return errOuter
+	})
+
+	err := s.ctx.PevalString(`call(function() { fail() })`)
+	c.Assert(err, Equals, errOuter)
+	c.Assert(s.ctx.GoErrors(), DeepEquals, []error{errGoSentinel, errOuter})
+}
+
+func (s *CandySuite) TestGoErrorsNestedThrows(c *C) {
+	var returned []error
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+	s.ctx.PushGlobalGoFunction("call", func(f func() error) error {
+		err := f()
+		returned = append(returned, err)
+		return err
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		var caught = [];
+		try {
+			call(function() {
+				try {
+					call(function() { fail() });
+				} catch (e) {
+					caught.push(e.message);
+				}
+
+				call(function() { fail() });
+			});
+		} catch (e) {
+			caught.push(e.message);
+		}
+
+		store(caught);
+	`), IsNil)
+
+	c.Assert(s.stored, DeepEquals, []interface{}{"sentinel", "sentinel"})
+	c.Assert(returned, DeepEquals, []error{errGoSentinel, errGoSentinel, errGoSentinel})
+	c.Assert(s.ctx.GoErrors(), DeepEquals, []error{
+		errGoSentinel, errGoSentinel, errGoSentinel, errGoSentinel, errGoSentinel,
+	})
+}
+
+func (s *CandySuite) TestGoErrorsCaught(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+	s.ctx.PushGlobalGoFunction("ok", func() int { return 42 })
+
+	c.Assert(s.ctx.PevalString(`try { fail() } catch (e) {}; ok()`), IsNil)
+	c.Assert(s.ctx.GoErrors(), DeepEquals, []error{errGoSentinel})
+
+	c.Assert(s.ctx.PevalString(`ok()`), IsNil)
+	c.Assert(s.ctx.GoErrors(), HasLen, 0)
+}
+
+func (s *CandySuite) TestGoErrorsNestedEvaluation(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+	s.ctx.PushGlobalGoFunction("eval", func(src string) error {
+		err := s.ctx.PevalString(src)
+		s.ctx.Pop()
+		return err
+	})
+
+	err := s.ctx.PevalString(`eval("fail()")`)
+	c.Assert(err, Equals, errGoSentinel)
+	c.Assert(s.ctx.GoErrors(), DeepEquals, []error{errGoSentinel, errGoSentinel})
+}
+
+func (s *CandySuite) TestGoErrorsLast(c *C) {
+	errOther := errors.New("other")
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+	s.ctx.PushGlobalGoFunction("failOther", func() error { return errOther })
+	s.ctx.PushGlobalGoFunction("ok", func() int { return 42 })
+
+	c.Assert(s.ctx.PevalString(`try { fail() } catch (e) {}; failOther()`), Equals, errOther)
+	c.Assert(s.ctx.GoErrors(), DeepEquals, []error{errGoSentinel, errOther})
+
+	c.Assert(s.ctx.PevalString(`try { fail() } catch (e) {}; ok()`), IsNil)
+	c.Assert(s.ctx.GoErrors(), DeepEquals, []error{errGoSentinel})
+
+	c.Assert(s.ctx.PevalString(`ok()`), IsNil)
+	c.Assert(s.ctx.GoErrors(), HasLen, 0)
+}
+
+func (s *CandySuite) TestGoErrorsCallbackFromGo(c *C) {
+	var fn func() error
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+	s.ctx.PushGlobalGoFunction("keep", func(f func() error) { fn = f })
+
+	c.Assert(s.ctx.PevalString(`fail()`), Equals, errGoSentinel)
+	c.Assert(s.ctx.PevalString(`keep(function() { fail() })`), IsNil)
+	c.Assert(s.ctx.GoErrors(), HasLen, 0)
+
+	for i := 0; i < 2; i++ {
+		c.Assert(fn(), Equals, errGoSentinel)
+		c.Assert(s.ctx.GoErrors(), DeepEquals, []error{errGoSentinel})
+	}
+}
+
+func (s *CandySuite) TestGoErrorsDuktapeCall(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+
+	c.Assert(s.ctx.PevalString(`fail()`), Equals, errGoSentinel)
+	for i := 0; i < 2; i++ {
+		c.Assert(s.ctx.Context.PevalString(`fail()`), NotNil)
+		c.Assert(s.ctx.GoErrors(), DeepEquals, []error{errGoSentinel})
+		s.ctx.Pop()
+	}
+}
+
+func (s *CandySuite) TestGoErrorsBounded(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+
+	c.Assert(s.ctx.PevalString(`
+		for (var i = 0; i < 2 * 64; i++) {
+			try { fail() } catch (e) {}
+		}
+	`), IsNil)
+	c.Assert(s.ctx.GoErrors(), HasLen, maxGoErrors)
+}
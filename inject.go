@@ -0,0 +1,74 @@
+package candyjs
+
+import (
+	"context"
+	"reflect"
+)
+
+// This is the JS receiver, the `this` of the call, of a Go function called
+// from JS, as decoded into an interface{}: the value proxied or a plain value.
+// The leading parameters of the Go functions of types This, *Context and
+// context.Context are filled in without consuming JS arguments. Example:
+//
+//	ctx.PushGlobalGoFunction("load", func(ctx *candyjs.Context, pckgName string) error {
+//		return ctx.PushGlobalPackage(pckgName, pckgName)
+//	})
+//
+//	ctx.PushGlobalGoFunction("fetch", func(c context.Context, this candyjs.This, url string) {
+//		...
+//	})
+//
+// The context.Context is the one passed to the current evaluation, see
+// PevalStringContext, or context.Background.
+type This interface{}
+
+var (
+	typeContext   = reflect.TypeOf((*Context)(nil))
+	typeGoContext = reflect.TypeOf((*context.Context)(nil)).Elem()
+	typeThis      = reflect.TypeOf((*This)(nil)).Elem()
+)
+
+// injectedCount returns the number of leading parameters of the function type
+// def filled in by injectArgs.
+func injectedCount(def reflect.Type) int {
+	n := 0
+	for n < def.NumIn() && isInjected(def.In(n)) {
+		n++
+	}
+
+	return n
+}
+
+func isInjected(t reflect.Type) bool {
+	return t == typeContext || t == typeGoContext || t == typeThis
+}
+
+// injectArgs returns the values of the first n parameters of def.
+func (ctx *Context) injectArgs(def reflect.Type, n int) []reflect.Value {
+	args := make([]reflect.Value, 0, def.NumIn())
+	for i := 0; i < n; i++ {
+		switch def.In(i) {
+		case typeContext:
+			args = append(args, reflect.ValueOf(ctx))
+		case typeGoContext:
+			args = append(args, reflect.ValueOf(ctx.goContext()))
+		case typeThis:
+			args = append(args, ctx.getThis())
+		}
+	}
+
+	return args
+}
+
+// getThis returns the receiver of the current call as a This value.
+func (ctx *Context) getThis() reflect.Value {
+	ctx.PushThis()
+	defer ctx.Pop()
+
+	var this This
+	if v := ctx.getValueFromContext(ctx.GetTopIndex(), typeInterface); v.IsValid() {
+		this = v.Interface()
+	}
+
+	return reflect.ValueOf(&this).Elem()
+}
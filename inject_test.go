@@ -0,0 +1,65 @@
+package candyjs
+
+import (
+	"context"
+
+	. "gopkg.in/check.v1"
+)
+
+type injectKey struct{}
+
+func (s *CandySuite) TestInjectContext(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(ctx *Context, i int) bool {
+		return ctx == s.ctx && i == 42
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test(42))`), IsNil)
+	c.Assert(s.stored, Equals, true)
+}
+
+func (s *CandySuite) TestInjectGoContext(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(c context.Context, s string) string {
+		v, _ := c.Value(injectKey{}).(string)
+		return v + s
+	})
+
+	goCtx := context.WithValue(context.Background(), injectKey{}, "foo")
+	c.Assert(s.ctx.PevalStringContext(goCtx, `store(test("bar"))`), IsNil)
+	c.Assert(s.stored, Equals, "foobar")
+
+	c.Assert(s.ctx.PevalString(`store(test("bar"))`), IsNil)
+	c.Assert(s.stored, Equals, "bar")
+}
+
+func (s *CandySuite) TestInjectThis(c *C) {
+	s.ctx.PushGlobalProxy("ms", &MyStruct{Int: 21})
+	s.ctx.PushGlobalGoFunction("multiply", func(this This, x int) int {
+		return this.(*MyStruct).Int * x
+	})
+
+	c.Assert(s.ctx.PevalString(`store(multiply.call(ms, 2))`), IsNil)
+	c.Assert(s.stored, Equals, 42.0)
+}
+
+func (s *CandySuite) TestInjectThis_Plain(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(this This) interface{} {
+		return this
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test.call({foo: "bar"}))`), IsNil)
+	c.Assert(s.stored, DeepEquals, map[string]interface{}{"foo": "bar"})
+
+	c.Assert(s.ctx.PevalString(`store(test())`), IsNil)
+	c.Assert(s.stored, IsNil)
+}
+
+func (s *CandySuite) TestInjectAll(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(ctx *Context, c context.Context, this This, xs ...int) int {
+		return len(xs)
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test(1, 2, 3))`), IsNil)
+	c.Assert(s.stored, Equals, 3.0)
+
+	c.Assert(s.ctx.PevalString(`test("foo")`), ErrorMatches, "test: argument 1 expected int, got string")
+}
@@ -0,0 +1,98 @@
+package candyjs
+
+import "github.com/crazytyper/go-duktape"
+
+// maxCauseDepth limits the chain of causes decoded by getJSError.
+const maxCauseDepth = 16
+
+// JSError is a JS exception, like a thrown Error, received by Go. It is
+// returned by the evaluations and by the JS functions called from Go.
+type JSError struct {
+	// Name is the name of the error, like TypeError or RangeError.
+	Name string
+	// Message is the message of the error, or the string value of a thrown
+	// value that is not an object.
+	Message string
+	// Stack is the JS stack trace.
+	Stack string
+	// FileName and LineNumber are the location where the error was created.
+	FileName   string
+	LineNumber int
+	// Cause is the error found in the `cause` property, if any.
+	Cause error
+	// Properties are the own enumerable properties of the error.
+	Properties map[string]interface{}
+}
+
+func (e *JSError) Error() string {
+	if e.Name == "" {
+		return e.Message
+	}
+
+	return e.Name + ": " + e.Message
+}
+
+// Code returns ErrorCodeJSError.
+func (e *JSError) Code() string {
+	return ErrorCodeJSError
+}
+
+// Unwrap returns the cause of the error.
+func (e *JSError) Unwrap() error {
+	return e.Cause
+}
+
+var _ Error = (*JSError)(nil)
+
+// getJSError returns the JSError of the thrown value at the given index.
+func (ctx *Context) getJSError(index int) *JSError {
+	return ctx.getJSErrorDepth(ctx.NormalizeIndex(index), 0)
+}
+
+func (ctx *Context) getJSErrorDepth(index, depth int) *JSError {
+	if !ctx.IsObject(index) {
+		return &JSError{Message: ctx.SafeToString(index)}
+	}
+
+	e := &JSError{
+		Name:       ctx.getStringProp(index, "name"),
+		Message:    ctx.getStringProp(index, "message"),
+		Stack:      ctx.getStringProp(index, "stack"),
+		FileName:   ctx.getStringProp(index, "fileName"),
+		Properties: make(map[string]interface{}),
+	}
+
+	ctx.GetPropString(index, "lineNumber")
+	if ctx.IsNumber(-1) {
+		e.LineNumber = ctx.GetInt(-1)
+	}
+	ctx.Pop()
+
+	ctx.GetPropString(index, "cause")
+	if depth < maxCauseDepth && !ctx.IsUndefined(-1) && !ctx.IsNull(-1) {
+		if err := ctx.getGoError(-1); err != nil {
+			e.Cause = err
+		} else {
+			e.Cause = ctx.getJSErrorDepth(ctx.NormalizeIndex(-1), depth+1)
+		}
+	}
+	ctx.Pop()
+
+	ctx.Enum(index, duktape.EnumOwnPropertiesOnly)
+	for ctx.Next(-1, true) {
+		e.Properties[ctx.SafeToString(-2)] = ctx.getValueFromContext(-1, typeInterface).Interface()
+		ctx.Pop2()
+	}
+	ctx.Pop()
+
+	return e
+}
+
+func (ctx *Context) getStringProp(index int, key string) string {
+	defer ctx.Pop()
+	if !ctx.GetPropString(index, key) || ctx.IsUndefined(-1) {
+		return ""
+	}
+
+	return ctx.SafeToString(-1)
+}
@@ -0,0 +1,71 @@
+package candyjs
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestJSError(c *C) {
+	err := s.ctx.PevalString(`
+		var e = new RangeError("foo");
+		e.status = 404;
+		throw e;
+	`)
+
+	jsErr, ok := err.(*JSError)
+	c.Assert(ok, Equals, true)
+	c.Assert(jsErr.Name, Equals, "RangeError")
+	c.Assert(jsErr.Message, Equals, "foo")
+	c.Assert(jsErr.FileName, Equals, "eval")
+	c.Assert(jsErr.LineNumber, Equals, 2)
+	c.Assert(jsErr.Stack, Matches, "(?s)RangeError: foo\n.*eval.*")
+	c.Assert(jsErr.Properties, DeepEquals, map[string]interface{}{"status": 404.0})
+	c.Assert(jsErr.Error(), Equals, "RangeError: foo")
+	c.Assert(ErrorCode(err), Equals, ErrorCodeJSError)
+}
+
+func (s *CandySuite) TestJSErrorTypeError(c *C) {
+	err := s.ctx.PevalString(`undefined.foo`)
+
+	jsErr, ok := err.(*JSError)
+	c.Assert(ok, Equals, true)
+	c.Assert(jsErr.Name, Equals, "TypeError")
+}
+
+func (s *CandySuite) TestJSErrorNotObject(c *C) {
+	err := s.ctx.PevalString(`throw "foo"`)
+	c.Assert(err, DeepEquals, &JSError{Message: "foo"})
+}
+
+func (s *CandySuite) TestJSErrorCause(c *C) {
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errGoSentinel })
+
+	err := s.ctx.PevalString(`
+		try {
+			fail();
+		} catch (e) {
+			var wrapped = new Error("wrapped");
+			wrapped.cause = e;
+			var outer = new Error("outer");
+			outer.cause = wrapped;
+			throw outer;
+		}
+	`)
+
+	c.Assert(err, ErrorMatches, "Error: outer")
+	c.Assert(errors.Unwrap(err), ErrorMatches, "Error: wrapped")
+	c.Assert(errors.Is(err, errGoSentinel), Equals, true)
+}
+
+func (s *CandySuite) TestJSErrorCallback(c *C) {
+	var err error
+	s.ctx.PushGlobalGoFunction("call", func(f func() error) { err = f() })
+
+	c.Assert(s.ctx.PevalString(`call(function() { throw new SyntaxError("foo") })`), IsNil)
+
+	var jsErr *JSError
+	c.Assert(errors.As(err, &jsErr), Equals, true)
+	c.Assert(jsErr.Name, Equals, "SyntaxError")
+	c.Assert(jsErr.Message, Equals, "foo")
+}
@@ -0,0 +1,175 @@
+package candyjs
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/crazytyper/go-duktape"
+)
+
+// PushGlobalOverloaded pushes to the global object a function dispatching to
+// one of the Go functions fns, Go has no overloading but the JS API can expose
+// several signatures under a single name:
+//
+//	ctx.PushGlobalOverloaded("format",
+//		func(d time.Time) string { return d.Format(time.RFC3339) },
+//		func(d time.Time, layout string) string { return d.Format(layout) },
+//		func(n float64) string { return strconv.FormatFloat(n, 'f', -1, 64) },
+//	)
+//
+// At call time the first function whose arity and parameter types match the
+// arguments is called, the types follow the rules of the strict arguments, see
+// SetStrictArguments. The functions taking the primitives as they are, the
+// numbers as numeric types, the strings as strings and the dates as time.Time,
+// are preferred over the functions converting them, like a number into a
+// time.Time, otherwise the order matters. When no function matches a
+// TypeError listing the candidate signatures is thrown.
+//
+// The functions can be given as Func, to set their return style and the names
+// of their results.
+func (ctx *Context) PushGlobalOverloaded(name string, fns ...interface{}) (int, error) {
+	if err := checkOverloads(name, fns); err != nil {
+		return 0, err
+	}
+
+	return ctx.pushGlobalNative(name, ctx.wrapOverloaded(name, fns))
+}
+
+// PushOverloaded like PushGlobalOverloaded but pushed to the stack.
+func (ctx *Context) PushOverloaded(fns ...interface{}) (int, error) {
+	name := "function"
+	if err := checkOverloads(name, fns); err != nil {
+		return 0, err
+	}
+
+	return ctx.pushNative(ctx.wrapOverloaded(name, fns)), nil
+}
+
+func checkOverloads(name string, fns []interface{}) error {
+	if len(fns) == 0 {
+		return errorf(ErrorCodeNotCallable, "%s: no functions to overload", name)
+	}
+
+	for _, f := range fns {
+		f = overloadFunc(f).Fn
+		if f == nil || reflect.TypeOf(f).Kind() != reflect.Func {
+			return errorf(ErrorCodeNotCallable, "%s: type %T is not a function", name, f)
+		}
+	}
+
+	return nil
+}
+
+// overloadFunc returns the given function as a Func.
+func overloadFunc(f interface{}) Func {
+	if fn, ok := f.(Func); ok {
+		return fn
+	}
+
+	return Func{Fn: f}
+}
+
+func (ctx *Context) wrapOverloaded(name string, fns []interface{}) func(*duktape.Context) int {
+	funcs := make([]Func, len(fns))
+	for i, f := range fns {
+		funcs[i] = overloadFunc(f)
+	}
+
+	return ctx.recoverPanics(func(*duktape.Context) int {
+		for _, exact := range []bool{true, false} {
+			for _, fn := range funcs {
+				if !ctx.matchArgs(name, fn.Fn, exact) {
+					continue
+				}
+
+				style := ctx.returnStyle(fn.Returns)
+				names := ctx.resultNamesOf(reflect.TypeOf(fn.Fn), fn.Results)
+				return ctx.callFunctionReturning(fn.Fn, ctx.getFunctionArgs(fn.Fn), style, names)
+			}
+		}
+
+		return ctx.throwGoError(ctx.overloadError(name, fns))
+	})
+}
+
+// matchArgs returns true if the arguments in the stack match the parameters
+// of f, unlike checkArgs the arity must match: neither the missing nor the
+// extra arguments match. If exact the primitives must not be converted, see
+// isExactKind.
+func (ctx *Context) matchArgs(name string, f interface{}, exact bool) bool {
+	def := reflect.TypeOf(f)
+	injected := injectedCount(def)
+	inCount := def.NumIn() - injected
+	argc := ctx.GetTop()
+	switch {
+	case def.IsVariadic() && argc < inCount-1:
+		return false
+	case !def.IsVariadic() && argc != inCount:
+		return false
+	}
+
+	if ctx.checkArgs(name, f) != nil {
+		return false
+	}
+
+	for index := 0; exact && index < argc; index++ {
+		var t reflect.Type
+		if def.IsVariadic() && index >= inCount-1 {
+			t = def.In(def.NumIn() - 1).Elem()
+		} else {
+			t = def.In(injected + index)
+		}
+
+		if !ctx.isExactKind(index, t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isExactKind returns false if the primitive at the given index would be
+// converted into a value of another kind of type t, like a number into a
+// time.Time or a string into a number.
+func (ctx *Context) isExactKind(index int, t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+
+	switch {
+	case ctx.IsNumber(index):
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+
+		return false
+	case ctx.IsString(index):
+		return t.Kind() == reflect.String
+	case ctx.isDate(index):
+		return isTimeType(t)
+	}
+
+	return true
+}
+
+func (ctx *Context) overloadError(name string, fns []interface{}) error {
+	args := make([]string, ctx.GetTop())
+	for i := range args {
+		args[i] = ctx.typeName(i)
+	}
+
+	candidates := make([]string, len(fns))
+	for i, f := range fns {
+		candidates[i] = reflect.TypeOf(overloadFunc(f).Fn).String()
+	}
+
+	return errorf(ErrorCodeTypeError, "%s: no overload matches (%s), candidates: %s",
+		name, strings.Join(args, ", "), strings.Join(candidates, "; "))
+}
@@ -0,0 +1,109 @@
+package candyjs
+
+import (
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) pushFormat(c *C) {
+	_, err := s.ctx.PushGlobalOverloaded("format",
+		func(d time.Time) string { return d.Format("2006-01-02") },
+		func(d time.Time, layout string) string { return d.Format(layout) },
+		func(n float64) string { return strconv.FormatFloat(n, 'f', 2, 64) },
+	)
+	c.Assert(err, IsNil)
+}
+
+func (s *CandySuite) TestPushGlobalOverloaded(c *C) {
+	s.pushFormat(c)
+
+	c.Assert(s.ctx.PevalString(`store([
+		format(new Date(Date.UTC(1999, 9, 19))),
+		format(new Date(Date.UTC(1999, 9, 19)), "Jan 2006")
+	])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"1999-10-19", "Oct 1999"})
+}
+
+func (s *CandySuite) TestPushGlobalOverloaded_ExactKind(c *C) {
+	s.pushFormat(c)
+
+	c.Assert(s.ctx.PevalString(`store([format(42), format(1.5)])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"42.00", "1.50"})
+
+	_, err := s.ctx.PushGlobalOverloaded("day",
+		func(d time.Time) int { return d.Day() },
+	)
+	c.Assert(err, IsNil)
+
+	c.Assert(s.ctx.PevalString(`store(day(Date.UTC(1999, 9, 19)))`), IsNil)
+	c.Assert(s.stored, Equals, 19.0)
+}
+
+func (s *CandySuite) TestPushGlobalOverloaded_Order(c *C) {
+	_, err := s.ctx.PushGlobalOverloaded("kind",
+		func(s string) string { return "string" },
+		func(n float64) string { return "number" },
+		func(m map[string]interface{}) string { return "object" },
+		func(is ...int) string { return "ints" },
+	)
+	c.Assert(err, IsNil)
+
+	c.Assert(s.ctx.PevalString(`store([kind("foo"), kind(42), kind({}), kind(), kind(1, 2)])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"string", "number", "object", "ints", "ints"})
+}
+
+func (s *CandySuite) TestPushGlobalOverloaded_Func(c *C) {
+	_, err := s.ctx.PushGlobalOverloaded("atoi",
+		Func{Fn: strconv.Atoi, Returns: ReturnArray},
+		Func{
+			Fn:      func(s string, base int) (int64, error) { return strconv.ParseInt(s, base, 64) },
+			Results: []string{"value"},
+			Returns: ReturnObject,
+		},
+	)
+	c.Assert(err, IsNil)
+
+	c.Assert(s.ctx.PevalString(`store([atoi("42")[0], atoi("foo")[1].message, atoi("ff", 16).value])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		42.0, `strconv.Atoi: parsing "foo": invalid syntax`, 255.0,
+	})
+}
+
+func (s *CandySuite) TestPushGlobalOverloaded_NoMatch(c *C) {
+	s.pushFormat(c)
+
+	c.Assert(s.ctx.PevalString(`
+		try {
+			format("foo", 42)
+		} catch (e) {
+			store([e instanceof TypeError, e.message])
+		}
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		true, "format: no overload matches (string, number), candidates: " +
+			"func(time.Time) string; func(time.Time, string) string; func(float64) string",
+	})
+}
+
+func (s *CandySuite) TestPushGlobalOverloaded_NotFunction(c *C) {
+	_, err := s.ctx.PushGlobalOverloaded("format", 42)
+	c.Assert(err, ErrorMatches, "format: type int is not a function")
+	c.Assert(ErrorCode(err), Equals, ErrorCodeNotCallable)
+
+	_, err = s.ctx.PushGlobalOverloaded("format")
+	c.Assert(ErrorCode(err), Equals, ErrorCodeNotCallable)
+}
+
+func (s *CandySuite) TestPushOverloaded(c *C) {
+	_, err := s.ctx.PushOverloaded(
+		func(s string) string { return "string" },
+		func(n float64) string { return "number" },
+	)
+	c.Assert(err, IsNil)
+	s.ctx.PutGlobalString("kind")
+
+	c.Assert(s.ctx.PevalString(`store([kind("foo"), kind(42)])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"string", "number"})
+}
@@ -0,0 +1,72 @@
+package candyjs
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/crazytyper/go-duktape"
+)
+
+// PanicError is the error thrown in JS for a panic recovered in a Go function
+// called from JS, the JS error carries the Go stack in its `goStack` property.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the Go stack of the goroutine when it panicked.
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Code returns ErrorCodePanic.
+func (e *PanicError) Code() string {
+	return ErrorCodePanic
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+var _ Error = (*PanicError)(nil)
+
+// SetRepanic sets whether the panics of the Go functions called from JS are
+// propagated, instead of being thrown as JS errors. Propagating a panic
+// through duktape leaves the context in an undefined state, use it only for
+// debugging.
+func (ctx *Context) SetRepanic(repanic bool) {
+	ctx.repanic = repanic
+}
+
+// pushGoFunction pushes a raw duktape function recovering its panics.
+func (ctx *Context) pushGoFunction(fn func(*duktape.Context) int) int {
+	return ctx.pushNative(ctx.recoverPanics(fn))
+}
+
+// recoverPanics returns fn throwing the recovered panics as a *PanicError.
+func (ctx *Context) recoverPanics(fn func(*duktape.Context) int) func(*duktape.Context) int {
+	return func(d *duktape.Context) (rc int) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			if ctx.repanic {
+				panic(r)
+			}
+
+			err, ok := r.(*PanicError)
+			if !ok {
+				err = &PanicError{Value: r, Stack: string(debug.Stack())}
+			}
+
+			rc = ctx.throwGoError(err)
+		}()
+
+		return fn(d)
+	}
+}
@@ -0,0 +1,75 @@
+package candyjs
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestPanicFunction(c *C) {
+	s.ctx.PushGlobalGoFunction("boom", func() { panic("boom") })
+
+	c.Assert(s.ctx.PevalString(`
+		try {
+			boom()
+		} catch (e) {
+			store([e.message, e.code, e.goType, e.goStack.indexOf("panic_test.go") >= 0])
+		}
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		"panic: boom", ErrorCodePanic, "*candyjs.PanicError", true,
+	})
+}
+
+func (s *CandySuite) TestPanicPeval(c *C) {
+	errBoom := errors.New("boom")
+	s.ctx.PushGlobalGoFunction("boom", func() { panic(errBoom) })
+
+	err := s.ctx.PevalString(`boom()`)
+
+	var p *PanicError
+	c.Assert(errors.As(err, &p), Equals, true)
+	c.Assert(p.Value, Equals, errBoom)
+	c.Assert(p.Stack, Matches, "(?s).*panic_test.go.*")
+	c.Assert(errors.Is(err, errBoom), Equals, true)
+}
+
+func (s *CandySuite) TestPanicArguments(c *C) {
+	s.ctx.SetStrictArguments(false)
+	s.ctx.PushGlobalGoFunction("double", func(i int) int { return i * 2 })
+
+	err := s.ctx.PevalString(`double("foo")`)
+	c.Assert(ErrorCode(err), Equals, ErrorCodePanic)
+}
+
+func (s *CandySuite) TestPanicTrap(c *C) {
+	s.ctx.PushGlobalProxy("p", &ProxyFuncs{
+		GetFunc: func(t interface{}, k string, recv interface{}) (interface{}, error) {
+			panic("get " + k)
+		},
+	})
+
+	err := s.ctx.PevalString(`p.foo`)
+	c.Assert(err, ErrorMatches, "panic: get foo")
+}
+
+func (s *CandySuite) TestPanicCallback(c *C) {
+	s.ctx.PushGlobalGoFunction("boom", func() { panic("boom") })
+	s.ctx.PushGlobalGoFunction("call", func(f func()) { f() })
+
+	err := s.ctx.PevalString(`call(function() { boom() })`)
+
+	var p *PanicError
+	c.Assert(errors.As(err, &p), Equals, true)
+	c.Assert(p.Value, Equals, "boom")
+}
+
+func (s *CandySuite) TestPanicCallbackResult(c *C) {
+	s.ctx.PushGlobalGoFunction("call", func(f func() []int) []int { return f() })
+
+	err := s.ctx.PevalString(`call(function() { return "foo" })`)
+	c.Assert(ErrorCode(err), Equals, ErrorCodePanic)
+
+	c.Assert(s.ctx.PevalString(`store(call(function() { return [1] }))`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{1.0})
+}
@@ -0,0 +1,96 @@
+package candyjs
+
+import (
+	"reflect"
+	"strconv"
+)
+
+const (
+	stashPrototypes = "prototypes"
+	goPrototypeProp = "\xff" + "goPrototypeProp"
+)
+
+// prototypes holds the ids of the types with a prototype, the prototypes are
+// stored into the global stash by id.
+type prototypes map[reflect.Type]string
+
+// pushPrototype pushes the prototype of the given type, the prototype is
+// created on first use. The prototypes do not inherit from Object.prototype,
+// so the proxy internal keys like `toString` keep working.
+func (ctx *Context) pushPrototype(t reflect.Type) {
+	id, ok := ctx.prototypes[t]
+	if !ok {
+		id = strconv.Itoa(len(ctx.prototypes))
+		ctx.prototypes[t] = id
+	}
+
+	ctx.PushGlobalStash()
+	if !ctx.GetPropString(-1, stashPrototypes) {
+		ctx.Pop()
+		ctx.PushObject()
+		ctx.Dup(-1)
+		ctx.PutPropString(-3, stashPrototypes)
+	}
+
+	if !ctx.GetPropString(-1, id) {
+		ctx.Pop()
+		ctx.PushGlobalObject()
+		ctx.GetPropString(-1, "Object")
+		ctx.PushString("create")
+		ctx.PushNull()
+		ctx.CallProp(-3, 1)
+		ctx.Remove(-2)
+		ctx.Remove(-2)
+		ctx.PushTrue()
+		ctx.PutPropString(-2, goPrototypeProp)
+		ctx.Dup(-1)
+		ctx.PutPropString(-3, id)
+	}
+
+	ctx.Remove(-2)
+	ctx.Remove(-2)
+}
+
+// setPrototype sets the prototype of the type of v to the proxy target at the
+// given index, if the type or the type pointed by v has a prototype.
+func (ctx *Context) setPrototype(index int, v interface{}) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return
+	}
+
+	if _, ok := ctx.prototypes[t]; !ok {
+		if t.Kind() != reflect.Ptr {
+			return
+		}
+
+		if t = t.Elem(); ctx.prototypes[t] == "" {
+			return
+		}
+	}
+
+	index = ctx.NormalizeIndex(index)
+	ctx.pushPrototype(t)
+	ctx.SetPrototype(index)
+}
+
+// pushPrototypeProp pushes the property of the prototype chain of the proxy
+// target at the given index, returns false if the property does not exist or
+// the target has not the prototype of a type.
+func (ctx *Context) pushPrototypeProp(index int, key string) bool {
+	ctx.GetPrototype(index)
+	if !ctx.IsObject(-1) || !ctx.hasHiddenProp(-1, goPrototypeProp) || !ctx.HasPropString(-1, key) {
+		ctx.Pop()
+		return false
+	}
+
+	ctx.GetPropString(-1, key)
+	ctx.Remove(-2)
+	return true
+}
+
+func (ctx *Context) hasHiddenProp(index int, key string) bool {
+	defer ctx.Pop()
+	ctx.GetPropString(index, key)
+	return !ctx.IsUndefined(-1)
+}
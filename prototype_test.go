@@ -0,0 +1,103 @@
+package candyjs
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestPushGlobalType_InstanceOf(c *C) {
+	s.ctx.PushGlobalType("MyStruct", MyStruct{})
+	s.ctx.PushGlobalType("MyNestedStruct", MyNestedStruct{})
+	s.ctx.PushGlobalProxy("test", &MyStruct{Int: 21})
+	s.ctx.PushGlobalProxy("value", MyStruct{Int: 21})
+	s.ctx.PushGlobalProxy("untyped", &myCustomProxy{})
+
+	c.Assert(s.ctx.PevalString(`store([
+		new MyStruct() instanceof MyStruct,
+		new MyStruct() instanceof MyNestedStruct,
+		test instanceof MyStruct,
+		value instanceof MyStruct,
+		untyped instanceof MyStruct,
+		MyStruct.prototype.constructor === MyStruct,
+		new MyStruct().constructor === MyStruct
+	])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, false, true, true, false, true, true})
+}
+
+func (s *CandySuite) TestPushGlobalType_PrototypeMethods(c *C) {
+	s.ctx.PushGlobalType("MyStruct", MyStruct{})
+	s.ctx.PushGlobalProxy("test", &MyStruct{Int: 21})
+
+	c.Assert(s.ctx.PevalString(`
+		MyStruct.prototype.double = function() { return this.multiply(2); };
+		MyStruct.prototype.int = function() { return "shadowed"; };
+
+		var obj = new MyStruct();
+		obj.int = 4;
+		store([test.double(), obj.double(), obj.int, "double" in test, "foo" in test])
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{42.0, 8.0, 4.0, true, false})
+}
+
+func (s *CandySuite) TestPushGlobalType_SharedPrototype(c *C) {
+	s.ctx.PushGlobalType("A", MyStruct{})
+	s.ctx.PushGlobalType("B", MyStruct{})
+
+	c.Assert(s.ctx.PevalString(`store([A.prototype === B.prototype, new B() instanceof A])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, true})
+}
+
+func (s *CandySuite) TestPushGlobalType_InternalKeys(c *C) {
+	s.ctx.PushGlobalType("MyStruct", MyStruct{})
+
+	c.Assert(s.ctx.PevalString(`store("" + new MyStruct())`), IsNil)
+	c.Assert(s.stored, Equals, "[candyjs Proxy]")
+}
+
+func (s *CandySuite) TestPushGlobalType_ObjectLiteral(c *C) {
+	s.ctx.PushGlobalType("MyStruct", MyStruct{})
+
+	c.Assert(s.ctx.PevalString(`store(new MyStruct({int: 21, string: "foo", nested: new MyStruct({int: 42})}))`), IsNil)
+	c.Assert(s.stored.(*MyStruct).Int, Equals, 21)
+	c.Assert(s.stored.(*MyStruct).String, Equals, "foo")
+	c.Assert(s.stored.(*MyStruct).Nested.Int, Equals, 42)
+
+	c.Assert(s.ctx.PevalString(`
+		try {
+			new MyStruct({foo: 42});
+		} catch(err) {
+			store(true);
+		}
+	`), IsNil)
+	c.Assert(s.stored, Equals, true)
+}
+
+func (s *CandySuite) TestPushGlobalType_ObjectLiteralTypeError(c *C) {
+	s.ctx.PushGlobalType("MyStruct", MyStruct{})
+
+	c.Assert(s.ctx.PevalString(`
+		try {
+			new MyStruct({int: "x"});
+		} catch(err) {
+			store([err instanceof TypeError, err.message]);
+		}
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, "MyStruct: field int expected int, got string"})
+}
+
+func (s *CandySuite) TestPushGlobalType_Options(c *C) {
+	s.ctx.PushGlobalType("MyStruct", MyStruct{}, TypeOptions{
+		Constructor: func(i int, str string) *MyStruct {
+			return &MyStruct{Int: i, String: str}
+		},
+		Statics: map[string]interface{}{
+			"max":  42,
+			"zero": func() *MyStruct { return &MyStruct{} },
+		},
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		var obj = new MyStruct(21, "foo");
+		store([obj.int, obj.string, obj instanceof MyStruct, MyStruct.max, MyStruct.zero() instanceof MyStruct])
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{21.0, "foo", true, 42.0, true})
+}
@@ -3,11 +3,17 @@ package candyjs
 import "C"
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 )
 
 var (
-	p = &proxy{}
+	p = &ReflectProxy{}
+
+	// defaultProxy is used for values that neither implement Proxy nor
+	// ProxyProvider.
+	defaultProxy Proxy = p
 
 	//internalKeys map contains the keys that are called by duktape and cannot
 	//throw an error, the value of the map is the value returned when this keys
@@ -19,6 +25,11 @@ var (
 	}
 )
 
+// ToPrimitiveKey is the key passed to Proxy.Get for `Symbol.toPrimitive`, the
+// only symbol handed to the proxies. It is not valid UTF-8, so no JS property
+// name is received as such.
+const ToPrimitiveKey = symbolToPrimitive
+
 // Proxy defines the GO interface for ECMASCRIPTs proxy objects.
 type Proxy interface {
 	Has(t interface{}, k string) bool
@@ -27,25 +38,98 @@ type Proxy interface {
 	Enumerate(t interface{}) (interface{}, error)
 }
 
-type proxy struct{}
+// PrimitiveConverter is implemented by values converting themselves into a JS
+// primitive, like `Symbol.toPrimitive`. The hint is "string", "number" or
+// "default", explicit calls to `toString` and `valueOf` use "string" and
+// "number". By default the proxies of fmt.Stringer values are converted using
+// String and the proxies of numeric types into their number.
+type PrimitiveConverter interface {
+	ToPrimitive(hint string) interface{}
+}
+
+// ProxyProvider is implemented by values that are proxied by a Proxy other
+// than themselves, usually a ProxyFuncs.
+type ProxyProvider interface {
+	JSProxy() Proxy
+}
+
+// Deleter is implemented by proxies handling the `deleteProperty` trap, used
+// by the `delete` operator.
+type Deleter interface {
+	DeleteProperty(t interface{}, k string) (bool, error)
+}
+
+// Describer is implemented by proxies handling the `defineProperty` and
+// `getOwnPropertyDescriptor` traps. Duktape does not invoke these traps from
+// `Object.defineProperty` and `Object.getOwnPropertyDescriptor`, the scripts
+// use `CandyJS.defineProperty` and `CandyJS.getOwnPropertyDescriptor`, which
+// take the same arguments and also work on any other object. A nil descriptor
+// means the property does not exist.
+type Describer interface {
+	GetOwnPropertyDescriptor(t interface{}, k string) (*PropertyDescriptor, error)
+	DefineProperty(t interface{}, k string, d *PropertyDescriptor) (bool, error)
+}
+
+// Caller is implemented by proxies handling the `apply` trap. Proxies
+// implementing Caller or Constructor are pushed as functions.
+type Caller interface {
+	Apply(t interface{}, this interface{}, args []interface{}) (interface{}, error)
+}
+
+// Constructor is implemented by proxies handling the `construct` trap, used
+// by the `new` operator.
+type Constructor interface {
+	Construct(t interface{}, args []interface{}) (interface{}, error)
+}
+
+// PropertyDescriptor is the Go representation of a JS property descriptor,
+// accessor descriptors (get/set) are not supported.
+type PropertyDescriptor struct {
+	Value        interface{}
+	HasValue     bool
+	Writable     bool
+	Enumerable   bool
+	Configurable bool
+}
+
+// ReflectProxy is the default Proxy, it exposes the fields, map keys and
+// methods of the target using package reflect. It can be embedded or used as
+// fallback by custom proxies, see ProxyFuncs.
+type ReflectProxy struct{}
 
-func (p *proxy) Has(t interface{}, k string) bool {
-	_, err := p.getProperty(t, k)
+var (
+	_ Proxy       = (*ReflectProxy)(nil)
+	_ Deleter     = (*ReflectProxy)(nil)
+	_ Describer   = (*ReflectProxy)(nil)
+	_ Caller      = (*ReflectProxy)(nil)
+	_ Constructor = (*ReflectProxy)(nil)
+)
+
+func (p *ReflectProxy) Has(t interface{}, k string) bool {
+	_, err := p.Property(t, k)
 	return err == nil
 }
 
-func (p *proxy) Get(t interface{}, k string, recv interface{}) (interface{}, error) {
-	if k == "" {
+func (p *ReflectProxy) Get(t interface{}, k string, recv interface{}) (interface{}, error) {
+	if k == ToPrimitiveKey {
+		if f, ok := p.toPrimitive(t); ok {
+			return f, nil
+		}
+
 		k = "valueOf" // <- internal property
 	}
 
-	f, err := p.getProperty(t, k)
+	f, err := p.Property(t, k)
 	if err != nil {
 		if k == "toJSON" {
 			// use GO's JSON marshalling for proxies
 			// e.g. time.Time will correctly be marshalled into an RFC3339 date/time string-
 			//      without this it would get "{}"
-			return p.jsonMarshaller(t), nil
+			return p.JSONMarshaller(t), nil
+		}
+
+		if v, ok := p.primitive(t, k); ok {
+			return v, nil
 		}
 
 		if v, isInternal := internalKeys[k]; isInternal {
@@ -58,8 +142,69 @@ func (p *proxy) Get(t interface{}, k string, recv interface{}) (interface{}, err
 	return f.Interface(), nil
 }
 
-func (p *proxy) Set(t interface{}, k string, v, recv interface{}) (bool, error) {
-	f, err := p.getProperty(t, k)
+// primitive returns the `toString` and `valueOf` functions of the values
+// implementing PrimitiveConverter or fmt.Stringer, and of numeric types.
+func (p *ReflectProxy) primitive(t interface{}, k string) (interface{}, bool) {
+	if k != "toString" && k != "valueOf" {
+		return nil, false
+	}
+
+	f, ok := p.toPrimitive(t)
+	if !ok {
+		return nil, false
+	}
+
+	if k == "toString" {
+		if _, isConverter := t.(PrimitiveConverter); !isConverter && !isStringer(t) {
+			return nil, false
+		}
+
+		return func() interface{} { return f("string") }, true
+	}
+
+	return func() interface{} { return f("number") }, true
+}
+
+// toPrimitive returns the `Symbol.toPrimitive` function of the values
+// implementing PrimitiveConverter or fmt.Stringer, and of numeric types.
+func (p *ReflectProxy) toPrimitive(t interface{}) (func(hint string) interface{}, bool) {
+	if c, ok := t.(PrimitiveConverter); ok {
+		return c.ToPrimitive, true
+	}
+
+	s, isStringer := t.(fmt.Stringer)
+
+	var number func() float64
+	v := reflect.Indirect(reflect.ValueOf(t))
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number = func() float64 { return float64(v.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number = func() float64 { return float64(v.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		number = v.Float
+	}
+
+	if number == nil && !isStringer {
+		return nil, false
+	}
+
+	return func(hint string) interface{} {
+		if number == nil || (hint == "string" && isStringer) {
+			return s.String()
+		}
+
+		return number()
+	}, true
+}
+
+func isStringer(t interface{}) bool {
+	_, ok := t.(fmt.Stringer)
+	return ok
+}
+
+func (p *ReflectProxy) Set(t interface{}, k string, v, recv interface{}) (bool, error) {
+	f, err := p.Property(t, k)
 	if err != nil {
 		return false, err
 	}
@@ -70,7 +215,7 @@ func (p *proxy) Set(t interface{}, k string, v, recv interface{}) (bool, error)
 
 	value := reflect.Zero(f.Type())
 	if v != nil {
-		v, err = convert(f, v)
+		v, err = Convert(f.Type(), v)
 		if err != nil {
 			return false, nil
 		}
@@ -81,114 +226,222 @@ func (p *proxy) Set(t interface{}, k string, v, recv interface{}) (bool, error)
 	return true, nil
 }
 
-func (p *proxy) getProperty(t interface{}, key string) (reflect.Value, error) {
-	v := reflect.ValueOf(t)
-	r, found := p.getValueFromKind(key, v)
-	if !found {
-		return r, errorf(ErrorCodeUndefinedProperty, "Undefined property %q on type %T", key, t)
-	}
+func (p *ReflectProxy) DeleteProperty(t interface{}, k string) (bool, error) {
+	if v := reflect.Indirect(reflect.ValueOf(t)); v.Kind() == reflect.Map {
+		if v.Type().Key().Kind() != reflect.String {
+			return false, nil
+		}
 
-	return r, nil
-}
+		v.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), reflect.Value{})
+		return true, nil
+	}
 
-func (p *proxy) getValueFromKind(key string, v reflect.Value) (reflect.Value, bool) {
-	var value reflect.Value
-	var found bool
-	switch v.Kind() {
-	case reflect.Ptr:
-		value, found = p.getValueFromKindPtr(key, v)
-	case reflect.Struct:
-		value, found = p.getValueFromKindStruct(key, v)
-	case reflect.Map:
-		value, found = p.getValueFromKindMap(key, v)
+	f, err := p.Property(t, k)
+	if err != nil {
+		return true, nil // deleting an undefined property always succeeds
 	}
 
-	if !found {
-		return p.getMethod(key, v)
+	if !f.CanSet() {
+		return false, nil
 	}
 
-	return value, found
+	f.Set(reflect.Zero(f.Type()))
+	return true, nil
 }
 
-func (p *proxy) getValueFromKindPtr(key string, v reflect.Value) (reflect.Value, bool) {
-	r, found := p.getMethod(key, v)
-	if !found {
-		return p.getValueFromKind(key, v.Elem())
+func (p *ReflectProxy) GetOwnPropertyDescriptor(t interface{}, k string) (*PropertyDescriptor, error) {
+	f, err := p.Property(t, k)
+	if err != nil {
+		return nil, nil
 	}
 
-	return r, found
+	return &PropertyDescriptor{
+		Value:        f.Interface(),
+		HasValue:     true,
+		Writable:     f.CanSet(),
+		Enumerable:   true,
+		Configurable: true,
+	}, nil
 }
 
-func (p *proxy) getValueFromKindStruct(key string, v reflect.Value) (reflect.Value, bool) {
-	var r reflect.Value
-	fieldName := nameToFieldName(v.Type(), key)
-	if fieldName != "" {
-		r = v.FieldByName(fieldName)
+func (p *ReflectProxy) DefineProperty(t interface{}, k string, d *PropertyDescriptor) (bool, error) {
+	if !d.HasValue {
+		return p.Has(t, k), nil // only the attributes are changed, nothing to do
 	}
-	return r, r.IsValid()
+
+	return p.Set(t, k, d.Value, nil)
 }
 
-func (p *proxy) getValueFromKindMap(key string, v reflect.Value) (reflect.Value, bool) {
-	keyValue := reflect.ValueOf(key)
-	r := v.MapIndex(keyValue)
+func (p *ReflectProxy) Apply(t interface{}, this interface{}, args []interface{}) (interface{}, error) {
+	return p.call(t, args)
+}
 
-	return r, r.IsValid()
+func (p *ReflectProxy) Construct(t interface{}, args []interface{}) (interface{}, error) {
+	return p.call(t, args)
 }
 
-func (p *proxy) getMethod(key string, v reflect.Value) (reflect.Value, bool) {
-	var r reflect.Value
-	for _, name := range nameToGo(key) {
-		r = v.MethodByName(name)
-		if r.IsValid() {
-			break
-		}
+// methodProxy is implemented by proxies able to tell whether a key is a
+// method, the methods are cached per target.
+type methodProxy interface {
+	isMethod(t interface{}, k string) bool
+}
+
+// callableProxy is implemented by proxies that decide per target whether the
+// target must be pushed as a function.
+type callableProxy interface {
+	isCallable(t interface{}) bool
+}
+
+func isCallableProxy(proxy Proxy, t interface{}) bool {
+	if cp, ok := proxy.(callableProxy); ok {
+		return cp.isCallable(t)
 	}
 
-	return r, r.IsValid()
+	_, isCaller := proxy.(Caller)
+	_, isConstructor := proxy.(Constructor)
+	return isCaller || isConstructor
 }
 
-func (p *proxy) Enumerate(t interface{}) (interface{}, error) {
-	return p.getPropertyNames(t)
+func (p *ReflectProxy) isCallable(t interface{}) bool {
+	return reflect.Indirect(reflect.ValueOf(t)).Kind() == reflect.Func
 }
 
-func (p *proxy) getPropertyNames(t interface{}) ([]string, error) {
+func (p *ReflectProxy) call(t interface{}, args []interface{}) (interface{}, error) {
+	f := reflect.Indirect(reflect.ValueOf(t))
+	if f.Kind() != reflect.Func {
+		return nil, errorf(ErrorCodeNotCallable, "Type %T is not callable", t)
+	}
+
+	def := f.Type()
+	inCount := def.NumIn()
+	isVariadic := def.IsVariadic()
+
+	var in []reflect.Value
+	for i := 0; i < inCount || i < len(args); i++ {
+		var it reflect.Type
+		if isVariadic && i >= inCount-1 {
+			if i >= len(args) {
+				break
+			}
+			it = def.In(inCount - 1).Elem()
+		} else if i < inCount {
+			it = def.In(i)
+		} else {
+			break // extra arguments are discarded
+		}
+
+		if i >= len(args) || args[i] == nil {
+			in = append(in, reflect.Zero(it))
+			continue
+		}
+
+		v, err := Convert(it, args[i])
+		if err != nil {
+			return nil, err
+		}
+		in = append(in, reflect.ValueOf(v))
+	}
+
+	out := f.Call(in)
+	if c := len(out); c > 0 && out[c-1].Type() == errorInterface {
+		if !out[c-1].IsNil() {
+			return nil, out[c-1].Interface().(error)
+		}
+		out = out[:c-1]
+	}
+
+	switch len(out) {
+	case 0:
+		return nil, nil
+	case 1:
+		return out[0].Interface(), nil
+	}
+
+	results := make([]interface{}, len(out))
+	for i, v := range out {
+		results[i] = v.Interface()
+	}
+
+	return results, nil
+}
+
+// Property returns the field, map value or method of t named by the JS key.
+func (p *ReflectProxy) Property(t interface{}, key string) (reflect.Value, error) {
 	v := reflect.ValueOf(t)
+	r, found, _ := p.getValueFromKind(key, v)
+	if !found {
+		return r, errorf(ErrorCodeUndefinedProperty, "Undefined property %q on type %T", key, t)
+	}
 
-	var names []string
-	var err error
+	return r, nil
+}
+
+func (p *ReflectProxy) isMethod(t interface{}, k string) bool {
+	_, found, isMethod := p.getValueFromKind(k, reflect.ValueOf(t))
+	return found && isMethod
+}
+
+func (p *ReflectProxy) getValueFromKind(key string, v reflect.Value) (value reflect.Value, found, isMethod bool) {
+	if !v.IsValid() {
+		return v, false, false
+	}
+
+	m := getTypeInfo(v.Type()).member(key)
 	switch v.Kind() {
 	case reflect.Ptr:
-		names, err = p.getPropertyNames(v.Elem().Interface())
-		if err != nil {
-			return nil, err
+		if m.method != -1 {
+			return v.Method(m.method), true, true
 		}
+
+		return p.getValueFromKind(key, v.Elem())
 	case reflect.Struct:
-		visitFields(v.Type(), func(f reflect.StructField) bool {
-			names = append(names, fieldToName(f))
-			return false
-		})
+		if m.field != nil {
+			return v.FieldByIndex(m.field), true, false
+		}
+	case reflect.Map:
+		if r := v.MapIndex(reflect.ValueOf(key)); r.IsValid() {
+			return r, true, false
+		}
 	}
 
-	mCount := v.NumMethod()
-	for i := 0; i < mCount; i++ {
-		methodName := v.Type().Method(i).Name
-		if !isExported(methodName) {
-			continue
-		}
+	if m.method != -1 {
+		return v.Method(m.method), true, true
+	}
+
+	return reflect.Value{}, false, false
+}
 
-		names = append(names, nameToJavaScript(methodName))
+func (p *ReflectProxy) Enumerate(t interface{}) (interface{}, error) {
+	return p.getPropertyNames(t)
+}
+
+func (p *ReflectProxy) getPropertyNames(t interface{}) ([]string, error) {
+	if t == nil {
+		return nil, nil
 	}
 
-	return names, nil
+	names := getTypeInfo(reflect.TypeOf(t)).propertyNames()
+	return append([]string(nil), names...), nil
 }
 
-func convert(t reflect.Value, value interface{}) (interface{}, error) {
+// Convert converts a value received from JS into the given Go type, numbers
+// are casted, times and durations are decoded like the function arguments,
+// and any other value is converted using package encoding/json. The values
+// assignable to t, like the Go values of the proxies, are not converted.
+func Convert(t reflect.Type, value interface{}) (interface{}, error) {
+	if value == nil {
+		return reflect.Zero(t).Interface(), nil
+	}
 
 	s := reflect.ValueOf(value)
-	if t.Type() == s.Type() {
+	if s.Type().AssignableTo(t) {
 		return value, nil // no conversion required
 	}
 
+	if tm, ok, err := convertTime(t, value); ok {
+		return tm, err
+	}
+
 	value, ok := castNumberToGoType(t.Kind(), value)
 	if ok {
 		return value, nil
@@ -197,9 +450,9 @@ func convert(t reflect.Value, value interface{}) (interface{}, error) {
 	return convertUsingJSON(t, value)
 }
 
-func convertUsingJSON(t reflect.Value, value interface{}) (interface{}, error) {
+func convertUsingJSON(t reflect.Type, value interface{}) (interface{}, error) {
 
-	tv := reflect.New(t.Type()).Interface()
+	tv := reflect.New(t).Interface()
 	js, err := json.Marshal(value)
 	if err == nil {
 		err = json.Unmarshal([]byte(js), tv)
@@ -212,6 +465,10 @@ func convertUsingJSON(t reflect.Value, value interface{}) (interface{}, error) {
 }
 
 func castNumberToGoType(k reflect.Kind, v interface{}) (interface{}, bool) {
+	if _, ok := v.(float64); !ok {
+		return v, false
+	}
+
 	switch k {
 	case reflect.Int:
 		v = int(v.(float64))
@@ -242,7 +499,9 @@ func castNumberToGoType(k reflect.Kind, v interface{}) (interface{}, bool) {
 	return v, true
 }
 
-func (p *proxy) jsonMarshaller(t interface{}) interface{} {
+// JSONMarshaller returns the `toJSON` function of t, it uses package
+// encoding/json to marshal the value.
+func (p *ReflectProxy) JSONMarshaller(t interface{}) interface{} {
 	return func(val interface{}, key interface{}) interface{} {
 		js, err := json.Marshal(t)
 		if err != nil {
@@ -258,3 +517,183 @@ func (p *proxy) jsonMarshaller(t interface{}) interface{} {
 		return v
 	}
 }
+
+// ProxyFuncs is a Proxy that overrides single traps with functions, the traps
+// without function are handled by Fallback, or by a ReflectProxy if Fallback is
+// nil. Example, a computed property:
+//	&ProxyFuncs{GetFunc: func(t interface{}, k string, recv interface{}) (interface{}, error) {
+//		if k == "fullName" {
+//			return t.(*User).First + " " + t.(*User).Last, nil
+//		}
+//		return nil, ErrFallback
+//	}}
+//
+// Returning ErrFallback from any function hands the request over to the
+// fallback proxy, except from HasFunc which returns no error: it calls the
+// fallback proxy itself when needed.
+type ProxyFuncs struct {
+	Fallback Proxy
+
+	HasFunc                      func(t interface{}, k string) bool
+	GetFunc                      func(t interface{}, k string, recv interface{}) (interface{}, error)
+	SetFunc                      func(t interface{}, k string, v, recv interface{}) (bool, error)
+	EnumerateFunc                func(t interface{}) (interface{}, error)
+	DeletePropertyFunc           func(t interface{}, k string) (bool, error)
+	GetOwnPropertyDescriptorFunc func(t interface{}, k string) (*PropertyDescriptor, error)
+	DefinePropertyFunc           func(t interface{}, k string, d *PropertyDescriptor) (bool, error)
+	ApplyFunc                    func(t interface{}, this interface{}, args []interface{}) (interface{}, error)
+	ConstructFunc                func(t interface{}, args []interface{}) (interface{}, error)
+}
+
+// ErrFallback is returned by the functions of a ProxyFuncs to delegate the
+// trap to the fallback proxy.
+var ErrFallback = errors.New("candyjs: fallback")
+
+var (
+	_ Proxy       = (*ProxyFuncs)(nil)
+	_ Deleter     = (*ProxyFuncs)(nil)
+	_ Describer   = (*ProxyFuncs)(nil)
+	_ Caller      = (*ProxyFuncs)(nil)
+	_ Constructor = (*ProxyFuncs)(nil)
+)
+
+func (p *ProxyFuncs) fallback() Proxy {
+	if p.Fallback != nil {
+		return p.Fallback
+	}
+
+	return defaultProxy
+}
+
+func (p *ProxyFuncs) Has(t interface{}, k string) bool {
+	if p.HasFunc != nil {
+		return p.HasFunc(t, k)
+	}
+
+	return p.fallback().Has(t, k)
+}
+
+func (p *ProxyFuncs) Get(t interface{}, k string, recv interface{}) (interface{}, error) {
+	if p.GetFunc != nil {
+		v, err := p.GetFunc(t, k, recv)
+		if err != ErrFallback {
+			return v, err
+		}
+	}
+
+	return p.fallback().Get(t, k, recv)
+}
+
+func (p *ProxyFuncs) Set(t interface{}, k string, v, recv interface{}) (bool, error) {
+	if p.SetFunc != nil {
+		ok, err := p.SetFunc(t, k, v, recv)
+		if err != ErrFallback {
+			return ok, err
+		}
+	}
+
+	return p.fallback().Set(t, k, v, recv)
+}
+
+func (p *ProxyFuncs) Enumerate(t interface{}) (interface{}, error) {
+	if p.EnumerateFunc != nil {
+		keys, err := p.EnumerateFunc(t)
+		if err != ErrFallback {
+			return keys, err
+		}
+	}
+
+	return p.fallback().Enumerate(t)
+}
+
+func (p *ProxyFuncs) DeleteProperty(t interface{}, k string) (bool, error) {
+	if p.DeletePropertyFunc != nil {
+		ok, err := p.DeletePropertyFunc(t, k)
+		if err != ErrFallback {
+			return ok, err
+		}
+	}
+
+	if d, ok := p.fallback().(Deleter); ok {
+		return d.DeleteProperty(t, k)
+	}
+
+	return false, nil
+}
+
+func (p *ProxyFuncs) GetOwnPropertyDescriptor(t interface{}, k string) (*PropertyDescriptor, error) {
+	if p.GetOwnPropertyDescriptorFunc != nil {
+		d, err := p.GetOwnPropertyDescriptorFunc(t, k)
+		if err != ErrFallback {
+			return d, err
+		}
+	}
+
+	if d, ok := p.fallback().(Describer); ok {
+		return d.GetOwnPropertyDescriptor(t, k)
+	}
+
+	return nil, nil
+}
+
+func (p *ProxyFuncs) DefineProperty(t interface{}, k string, d *PropertyDescriptor) (bool, error) {
+	if p.DefinePropertyFunc != nil {
+		ok, err := p.DefinePropertyFunc(t, k, d)
+		if err != ErrFallback {
+			return ok, err
+		}
+	}
+
+	if d2, ok := p.fallback().(Describer); ok {
+		return d2.DefineProperty(t, k, d)
+	}
+
+	return false, nil
+}
+
+func (p *ProxyFuncs) Apply(t interface{}, this interface{}, args []interface{}) (interface{}, error) {
+	if p.ApplyFunc != nil {
+		v, err := p.ApplyFunc(t, this, args)
+		if err != ErrFallback {
+			return v, err
+		}
+	}
+
+	if c, ok := p.fallback().(Caller); ok {
+		return c.Apply(t, this, args)
+	}
+
+	return nil, errorf(ErrorCodeNotCallable, "Type %T is not callable", t)
+}
+
+func (p *ProxyFuncs) Construct(t interface{}, args []interface{}) (interface{}, error) {
+	if p.ConstructFunc != nil {
+		v, err := p.ConstructFunc(t, args)
+		if err != ErrFallback {
+			return v, err
+		}
+	}
+
+	if c, ok := p.fallback().(Constructor); ok {
+		return c.Construct(t, args)
+	}
+
+	return nil, errorf(ErrorCodeNotCallable, "Type %T is not a constructor", t)
+}
+
+func (p *ProxyFuncs) isMethod(t interface{}, k string) bool {
+	if p.GetFunc != nil {
+		return false // the methods of the fallback may be overridden
+	}
+
+	mp, ok := p.fallback().(methodProxy)
+	return ok && mp.isMethod(t, k)
+}
+
+func (p *ProxyFuncs) isCallable(t interface{}) bool {
+	if p.ApplyFunc != nil || p.ConstructFunc != nil {
+		return true
+	}
+
+	return isCallableProxy(p.fallback(), t)
+}
@@ -2,6 +2,7 @@ package candyjs
 
 import (
 	"encoding/json"
+	"reflect"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -172,3 +173,261 @@ type customMap map[string]int
 
 func (c customMap) FunctionWithoutPtr() {}
 func (c *customMap) FunctionWithPtr()   {}
+
+func (s *CandySuite) TestProxy_DeleteProperty(c *C) {
+	t := &MyStruct{Int: 42, String: "foo"}
+	deleted, err := p.DeleteProperty(t, "int")
+	c.Assert(err, IsNil)
+	c.Assert(deleted, Equals, true)
+	c.Assert(t.Int, Equals, 0)
+	c.Assert(t.String, Equals, "foo")
+
+	deleted, err = p.DeleteProperty(t, "multiply")
+	c.Assert(err, IsNil)
+	c.Assert(deleted, Equals, false)
+
+	m := map[string]int{"foo": 42, "bar": 21}
+	deleted, err = p.DeleteProperty(m, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(deleted, Equals, true)
+	c.Assert(m, DeepEquals, map[string]int{"bar": 21})
+}
+
+func (s *CandySuite) TestProxy_GetOwnPropertyDescriptor(c *C) {
+	d, err := p.GetOwnPropertyDescriptor(&MyStruct{Int: 42}, "int")
+	c.Assert(err, IsNil)
+	c.Assert(d, DeepEquals, &PropertyDescriptor{
+		Value: 42, HasValue: true, Writable: true, Enumerable: true, Configurable: true,
+	})
+
+	d, err = p.GetOwnPropertyDescriptor(&MyStruct{Int: 42}, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(d, IsNil)
+}
+
+func (s *CandySuite) TestProxy_DefineProperty(c *C) {
+	t := &MyStruct{}
+	defined, err := p.DefineProperty(t, "int", &PropertyDescriptor{Value: 42.0, HasValue: true})
+	c.Assert(err, IsNil)
+	c.Assert(defined, Equals, true)
+	c.Assert(t.Int, Equals, 42)
+
+	defined, err = p.DefineProperty(t, "int", &PropertyDescriptor{Enumerable: true})
+	c.Assert(err, IsNil)
+	c.Assert(defined, Equals, true)
+	c.Assert(t.Int, Equals, 42)
+}
+
+func (s *CandySuite) TestProxy_Apply(c *C) {
+	v, err := p.Apply(func(a, b int) int { return a * b }, nil, []interface{}{6.0, 7.0})
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, 42)
+
+	v, err = p.Apply(func(s string, is ...int) (string, int) { return s, len(is) }, nil, []interface{}{"foo", 1.0, 2.0})
+	c.Assert(err, IsNil)
+	c.Assert(v, DeepEquals, []interface{}{"foo", 2})
+
+	_, err = p.Apply(&MyStruct{}, nil, nil)
+	c.Assert(ErrorCode(err), Equals, ErrorCodeNotCallable)
+}
+
+func (s *CandySuite) TestProxyFuncs(c *C) {
+	var calls []string
+	proxy := &ProxyFuncs{
+		HasFunc: func(t interface{}, k string) bool {
+			return k == "fullName" || (k != "int" && p.Has(t, k))
+		},
+		GetFunc: func(t interface{}, k string, recv interface{}) (interface{}, error) {
+			calls = append(calls, "get("+k+")")
+			switch k {
+			case "fullName":
+				return t.(*MyNestedStruct).Name + " Doe", nil
+			case "int":
+				return nil, errorf(ErrorCodeUndefinedProperty, "hidden")
+			}
+			return nil, ErrFallback
+		},
+	}
+
+	t := &MyNestedStruct{Name: "John"}
+	v, err := proxy.Get(t, "fullName", nil)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "John Doe")
+
+	v, err = proxy.Get(t, "name", nil)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "John")
+
+	c.Assert(proxy.Has(t, "fullName"), Equals, true)
+	c.Assert(proxy.Has(t, "int"), Equals, false)
+
+	setted, err := proxy.Set(t, "name", "Jane", nil)
+	c.Assert(err, IsNil)
+	c.Assert(setted, Equals, true)
+	c.Assert(t.Name, Equals, "Jane")
+
+	c.Assert(calls, DeepEquals, []string{"get(fullName)", "get(name)"})
+	c.Assert(isCallableProxy(proxy, t), Equals, false)
+	c.Assert(isCallableProxy(proxy, func() {}), Equals, true)
+}
+
+func (s *CandySuite) TestProxyFuncs_Fallback(c *C) {
+	proxy := &ProxyFuncs{
+		EnumerateFunc: func(t interface{}) (interface{}, error) {
+			return nil, ErrFallback
+		},
+		DefinePropertyFunc: func(t interface{}, k string, d *PropertyDescriptor) (bool, error) {
+			if k == "name" {
+				return false, nil
+			}
+			return false, ErrFallback
+		},
+		ApplyFunc: func(t interface{}, this interface{}, args []interface{}) (interface{}, error) {
+			if len(args) == 0 {
+				return "none", nil
+			}
+			return nil, ErrFallback
+		},
+		ConstructFunc: func(t interface{}, args []interface{}) (interface{}, error) {
+			return nil, ErrFallback
+		},
+	}
+
+	t := &MyNestedStruct{Name: "John"}
+	keys, err := proxy.Enumerate(t)
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, []string{"name", "sayHello"})
+
+	ok, err := proxy.DefineProperty(t, "name", &PropertyDescriptor{Value: "Jane", HasValue: true})
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+	c.Assert(t.Name, Equals, "John")
+
+	m := &MyStruct{}
+	ok, err = proxy.DefineProperty(m, "int", &PropertyDescriptor{Value: 42.0, HasValue: true})
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	c.Assert(m.Int, Equals, 42)
+
+	double := func(is ...int) int { return 2 * len(is) }
+	v, err := proxy.Apply(double, nil, nil)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "none")
+
+	v, err = proxy.Apply(double, nil, []interface{}{1.0, 2.0})
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, 4)
+
+	v, err = proxy.Construct(func(name string) *MyNestedStruct { return &MyNestedStruct{Name: name} }, []interface{}{"Jane"})
+	c.Assert(err, IsNil)
+	c.Assert(v.(*MyNestedStruct).Name, Equals, "Jane")
+
+	// HasFunc returns no error, without it the fallback is used
+	c.Assert(proxy.Has(t, "name"), Equals, true)
+	c.Assert(proxy.Has(t, "foo"), Equals, false)
+}
+
+func (s *CandySuite) TestConvert(c *C) {
+	v, err := Convert(reflect.TypeOf(0), 42.0)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, 42)
+
+	v, err = Convert(reflect.TypeOf(""), nil)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "")
+
+	v, err = Convert(reflect.TypeOf(MyNestedStruct{}), map[string]interface{}{"name": "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, MyNestedStruct{Name: "foo"})
+}
+
+func (s *CandySuite) TestProxy_Primitive(c *C) {
+	s.ctx.PushGlobalProxy("duration", 90*time.Second)
+	s.ctx.PushGlobalProxy("month", time.October)
+	s.ctx.PushGlobalProxy("primitive", &customPrimitive{})
+	s.ctx.PushGlobalProxy("int", customInt(42))
+
+	c.Assert(s.ctx.PevalString(`store([
+		String(duration),
+		duration.toString(),
+		duration.valueOf(),
+		duration / 1e9,
+		duration > 60e9,
+		duration.minutes(),
+		String(month),
+		month == 10,
+		String(primitive),
+		primitive + 1,
+		int + 1
+	])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		"1m30s", "1m30s", 90e9, 90.0, true, 1.5, "October", true, "string", 43.0, 43.0,
+	})
+}
+
+func (s *CandySuite) TestProxy_EmptyKey(c *C) {
+	s.ctx.PushGlobalProxy("m", map[string]int{"": 42})
+	s.ctx.PushGlobalProxy("s", &MyStruct{Int: 42})
+
+	c.Assert(s.ctx.PevalString(`store([m[""], "" in m, String(m)])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{42.0, true, "[candyjs Proxy]"})
+
+	err := s.ctx.PevalString(`s[""]`)
+	c.Assert(err, ErrorMatches, `Undefined property "" on type \*candyjs.MyStruct`)
+	c.Assert(ErrorCode(err), Equals, ErrorCodeUndefinedProperty)
+}
+
+func (s *CandySuite) TestProxy_Symbols(c *C) {
+	// the builds without the Symbol global still have symbols, pushed using
+	// their internal representation
+	symbols := map[string]string{
+		"toPrimitive": "\x81Symbol.toPrimitive\xff",
+		"iterator":    "\x81Symbol.iterator\xff",
+		"toStringTag": "\x81Symbol.toStringTag\xff",
+		"local":       "\x81foo\xff1",
+		"global":      "\x80bar",
+	}
+
+	s.ctx.PushGlobalObject()
+	s.ctx.PushObject()
+	for name, symbol := range symbols {
+		s.ctx.Context.PushString(symbol)
+		s.ctx.PutPropString(-2, name)
+	}
+	s.ctx.PutPropString(-2, "symbols")
+	s.ctx.Pop()
+
+	var keys []string
+	s.ctx.PushGlobalProxy("duration", 90*time.Second)
+	s.ctx.PushGlobalProxy("keys", &ProxyFuncs{
+		GetFunc: func(t interface{}, k string, recv interface{}) (interface{}, error) {
+			keys = append(keys, k)
+			return nil, ErrFallback
+		},
+	})
+
+	c.Assert(s.ctx.PevalString(`store([
+		typeof duration[symbols.toPrimitive],
+		duration[symbols.iterator],
+		duration[symbols.toStringTag],
+		duration[symbols.local],
+		duration[symbols.global],
+		symbols.iterator in duration,
+		String(duration)
+	])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		"function", nil, nil, nil, nil, false, "1m30s",
+	})
+
+	c.Assert(s.ctx.PevalString(`keys[symbols.iterator]; keys[symbols.local]`), IsNil)
+	c.Assert(keys, HasLen, 0)
+}
+
+type customPrimitive struct{}
+
+func (c *customPrimitive) ToPrimitive(hint string) interface{} {
+	if hint == "string" {
+		return hint
+	}
+	return 42
+}
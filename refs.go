@@ -0,0 +1,88 @@
+package candyjs
+
+import (
+	"runtime"
+	"sync"
+)
+
+const heapStashRefs = "refs"
+
+// jsRefs keeps track of the JS values pinned in the heap stash.
+type jsRefs struct {
+	next int
+
+	sync.Mutex
+	released []int
+}
+
+// jsRef is a JS value pinned in the heap stash, so Go can keep it after the
+// native call that received it returns. The value is unpinned once the jsRef
+// is collected by the Go GC.
+type jsRef struct {
+	ctx *Context
+	id  int
+}
+
+// pinValue pins the value at the given index, see jsRef.
+func (ctx *Context) pinValue(index int) *jsRef {
+	ctx.releaseRefs()
+
+	index = ctx.NormalizeIndex(index)
+	ctx.refs.next++
+	ref := &jsRef{ctx: ctx, id: ctx.refs.next}
+
+	ctx.pushRefs()
+	ctx.Dup(index)
+	ctx.PutPropIndex(-2, uint(ref.id))
+	ctx.Pop()
+
+	runtime.SetFinalizer(ref, (*jsRef).release)
+	return ref
+}
+
+// push pushes the pinned value to the stack.
+func (r *jsRef) push() {
+	r.ctx.pushRefs()
+	r.ctx.GetPropIndex(-1, uint(r.id))
+	r.ctx.Remove(-2)
+}
+
+// release queues the value to be unpinned, it is called by the Go GC from its
+// own goroutine, so the value is deleted by the next call to releaseRefs, on
+// the next pin or call from Go into JS.
+func (r *jsRef) release() {
+	r.ctx.refs.Lock()
+	r.ctx.refs.released = append(r.ctx.refs.released, r.id)
+	r.ctx.refs.Unlock()
+}
+
+// releaseRefs unpins the values of the collected jsRef.
+func (ctx *Context) releaseRefs() {
+	ctx.refs.Lock()
+	released := ctx.refs.released
+	ctx.refs.released = nil
+	ctx.refs.Unlock()
+
+	if len(released) == 0 {
+		return
+	}
+
+	ctx.pushRefs()
+	for _, id := range released {
+		ctx.DelPropIndex(-1, uint(id))
+	}
+	ctx.Pop()
+}
+
+// pushRefs pushes the object holding the pinned values, creating it if needed.
+func (ctx *Context) pushRefs() {
+	ctx.PushHeapStash()
+	if !ctx.GetPropString(-1, heapStashRefs) {
+		ctx.Pop()
+		ctx.PushObject()
+		ctx.Dup(-1)
+		ctx.PutPropString(-3, heapStashRefs)
+	}
+
+	ctx.Remove(-2)
+}
@@ -0,0 +1,105 @@
+package candyjs
+
+import (
+	"io"
+	"runtime"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestRefsCallback(c *C) {
+	var handlers []func(int) int
+	s.ctx.PushGlobalGoFunction("handle", func(fn func(int) int) {
+		handlers = append(handlers, fn)
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		handle(function(x) { return x * 2 });
+		handle(function(x) { return x * 3 });
+	`), IsNil)
+	s.ctx.Pop()
+
+	// the stack is changed once the native calls returned
+	s.ctx.PushString("foo")
+	s.ctx.PushNumber(42)
+	c.Assert(handlers[0](21), Equals, 42)
+	c.Assert(handlers[1](21), Equals, 63)
+	s.ctx.Pop2()
+
+	s.ctx.PevalString(`Duktape.gc()`)
+	s.ctx.Pop()
+	c.Assert(handlers[0](2), Equals, 4)
+}
+
+func (s *CandySuite) TestRefsWriter(c *C) {
+	var w io.Writer
+	s.ctx.PushGlobalGoFunction("keep", func(writer io.Writer) { w = writer })
+
+	c.Assert(s.ctx.PevalString(`
+		var out = [];
+		keep({write: function(s) { out.push(s) }});
+	`), IsNil)
+	s.ctx.Pop()
+
+	_, err := io.WriteString(w, "foo")
+	c.Assert(err, IsNil)
+	c.Assert(s.ctx.PevalString(`store(out)`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"foo"})
+}
+
+func (s *CandySuite) TestRefsRelease(c *C) {
+	s.ctx.PushGlobalGoFunction("call", func(fn func()) { fn() })
+	c.Assert(s.ctx.PevalString(`call(function() {})`), IsNil)
+	s.ctx.Pop()
+
+	c.Assert(s.countRefs(), Equals, 1)
+
+	for i := 0; i < 100 && s.countRefs() > 0; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+		s.ctx.releaseRefs()
+	}
+
+	c.Assert(s.countRefs(), Equals, 0)
+}
+
+func (s *CandySuite) TestRefsReleaseOnEvaluation(c *C) {
+	s.ctx.PushGlobalGoFunction("call", func(fn func()) { fn() })
+	c.Assert(s.ctx.PevalString(`call(function() {})`), IsNil)
+	s.ctx.Pop()
+
+	c.Assert(s.countRefs(), Equals, 1)
+
+	for i := 0; i < 100 && s.countReleased() == 0; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+
+	c.Assert(s.countReleased(), Equals, 1)
+	c.Assert(s.countRefs(), Equals, 1)
+
+	c.Assert(s.ctx.PevalString(`42`), IsNil)
+	s.ctx.Pop()
+	c.Assert(s.countRefs(), Equals, 0)
+}
+
+func (s *CandySuite) countReleased() int {
+	s.ctx.refs.Lock()
+	defer s.ctx.refs.Unlock()
+
+	return len(s.ctx.refs.released)
+}
+
+func (s *CandySuite) countRefs() int {
+	s.ctx.pushRefs()
+	defer s.ctx.Pop()
+
+	s.ctx.PushString("Object.keys")
+	s.ctx.Eval()
+	s.ctx.Dup(-2)
+	s.ctx.Call(1)
+	defer s.ctx.Pop()
+
+	return s.ctx.GetLength(-1)
+}
@@ -0,0 +1,182 @@
+package candyjs
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// ReturnStyle is how the results of the Go functions called from JS are
+// returned, see SetReturnStyle.
+type ReturnStyle int
+
+const (
+	// ReturnDefault uses the ReturnStyle of the context, ReturnThrow unless
+	// set with SetReturnStyle.
+	ReturnDefault ReturnStyle = iota
+	// ReturnThrow throws the non-nil trailing errors, the other results are
+	// discarded. Several results are returned as an array.
+	ReturnThrow
+	// ReturnArray returns the results, errors included, as an array:
+	// `[value, error]`, the error is null if nil.
+	ReturnArray
+	// ReturnObject returns the results, errors included, as an object:
+	// `{value: value, error: error}`, the error is null if nil. Several
+	// results are named value0, value1... unless named, see SetResultNames.
+	ReturnObject
+)
+
+// SetReturnStyle sets how the results of the Go functions are returned to JS,
+// ReturnThrow by default. With ReturnArray or ReturnObject the errors are
+// returned as JS errors, like the thrown ones, instead of being thrown:
+//
+//	ctx.SetReturnStyle(candyjs.ReturnObject)
+//	ctx.PushGlobalGoFunction("atoi", strconv.Atoi)
+//	ctx.PevalString(`var r = atoi("foo"); if (r.error) { ... }`)
+//
+// The functions returning a single value, without an error, return the value
+// in any style. The style of a single function is set using Func.
+func (ctx *Context) SetReturnStyle(style ReturnStyle) {
+	ctx.returns = style
+}
+
+// Func is a Go function with options, it can be pushed with PushGoFunction
+// and PushGlobalGoFunction like the function itself:
+//
+//	ctx.PushGlobalGoFunction("atoi", candyjs.Func{
+//		Fn:      strconv.Atoi,
+//		Returns: candyjs.ReturnArray,
+//	})
+type Func struct {
+	// Fn is the Go function.
+	Fn interface{}
+	// Returns is the ReturnStyle of the function, the one of the context if
+	// ReturnDefault.
+	Returns ReturnStyle
+	// Results are the names of the results, the errors excluded, see
+	// SetResultNames. The names of the context are used if nil.
+	Results []string
+}
+
+// SetResultNames sets the names of the results of the functions of the type
+// of f, the errors excluded. The several results of these functions are
+// returned to JS as an object with the given names instead of an array, and
+// the JS functions converted into Go functions of this type can return such
+// objects:
+//
+//	ctx.SetResultNames((func(int, int) (int, int))(nil), "q", "r")
+//	ctx.PushGlobalGoFunction("divmod", func(a, b int) (int, int) { return a / b, a % b })
+//	ctx.PevalString(`divmod(7, 2).r`)
+//
+// The names apply to every function of this type, the Go functions and the
+// JS functions converted alike, so the signature should be specific enough.
+// The names of a single Go function are set using Func instead, like the
+// functions pushed by the packages generated with the candyjs command and its
+// --named-results flag.
+func (ctx *Context) SetResultNames(f interface{}, names ...string) {
+	if ctx.resultNames == nil {
+		ctx.resultNames = make(map[reflect.Type][]string)
+	}
+
+	ctx.resultNames[reflect.TypeOf(f)] = names
+}
+
+// resultNamesOf returns names, or the names of the results of the functions
+// of type t if nil.
+func (ctx *Context) resultNamesOf(t reflect.Type, names []string) []string {
+	if names != nil {
+		return names
+	}
+
+	return ctx.resultNames[t]
+}
+
+// returnStyle returns the style of a function with the given style.
+func (ctx *Context) returnStyle(style ReturnStyle) ReturnStyle {
+	if style == ReturnDefault {
+		style = ctx.returns
+	}
+
+	if style == ReturnDefault {
+		return ReturnThrow
+	}
+
+	return style
+}
+
+// pushResults pushes the results of a Go function returned in the given style,
+// ReturnArray or ReturnObject, and returns the duktape return code. The
+// objects are named by names, see resultName. The results that cannot be
+// pushed are thrown as a Go error.
+func (ctx *Context) pushResults(out []reflect.Value, style ReturnStyle, names []string) int {
+	top := ctx.GetTop()
+	hasError := len(out) > 0 && out[len(out)-1].Type() == errorInterface
+	values := out
+	if hasError {
+		values = out[:len(out)-1]
+	}
+
+	if !hasError && len(values) <= 1 {
+		if len(values) == 0 {
+			return 1
+		}
+
+		if err := ctx.pushValue(values[0]); err != nil {
+			ctx.SetTop(top)
+			return ctx.throwGoError(err)
+		}
+
+		return 1
+	}
+
+	var obj int
+	if style == ReturnObject {
+		obj = ctx.PushObject()
+	} else {
+		obj = ctx.PushArray()
+	}
+
+	for i, v := range values {
+		if err := ctx.pushValue(v); err != nil {
+			ctx.SetTop(top)
+			return ctx.throwGoError(err)
+		}
+
+		if style == ReturnObject {
+			ctx.PutPropString(obj, resultName(i, len(values), names))
+		} else {
+			ctx.PutPropIndex(obj, uint(i))
+		}
+	}
+
+	if !hasError {
+		return 1
+	}
+
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		ctx.pushGoError(err)
+	} else {
+		ctx.PushNull()
+	}
+
+	if style == ReturnObject {
+		ctx.PutPropString(obj, "error")
+	} else {
+		ctx.PutPropIndex(obj, uint(len(values)))
+	}
+
+	return 1
+}
+
+// resultName returns the name of the i-th of n results in the objects of
+// ReturnObject, names[i] if any.
+func resultName(i, n int, names []string) string {
+	if i < len(names) && names[i] != "" {
+		return names[i]
+	}
+
+	if n == 1 {
+		return "value"
+	}
+
+	return "value" + strconv.Itoa(i)
+}
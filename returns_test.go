@@ -0,0 +1,165 @@
+package candyjs
+
+import (
+	"errors"
+	"strconv"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestReturnArray(c *C) {
+	s.ctx.SetReturnStyle(ReturnArray)
+	s.ctx.PushGlobalGoFunction("atoi", strconv.Atoi)
+
+	c.Assert(s.ctx.PevalString(`store(atoi("42"))`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{42.0, nil})
+
+	c.Assert(s.ctx.PevalString(`
+		var r = atoi("foo");
+		store([r[0], r[1] instanceof Error, r[1].message, r[1].goType])
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		0.0, true, `strconv.Atoi: parsing "foo": invalid syntax`, "*strconv.NumError",
+	})
+	c.Assert(s.ctx.GoErrors(), HasLen, 0)
+}
+
+func (s *CandySuite) TestReturnObject(c *C) {
+	s.ctx.SetReturnStyle(ReturnObject)
+	s.ctx.PushGlobalGoFunction("atoi", strconv.Atoi)
+
+	c.Assert(s.ctx.PevalString(`store(atoi("42"))`), IsNil)
+	c.Assert(s.stored, DeepEquals, map[string]interface{}{"value": 42.0, "error": nil})
+
+	c.Assert(s.ctx.PevalString(`
+		var r = atoi("foo");
+		if (r.error) {
+			store(r.error.message)
+		}
+	`), IsNil)
+	c.Assert(s.stored, Equals, `strconv.Atoi: parsing "foo": invalid syntax`)
+}
+
+func (s *CandySuite) TestReturnObject_Multiple(c *C) {
+	s.ctx.SetReturnStyle(ReturnObject)
+	s.ctx.PushGlobalGoFunction("test", func() (int, string) { return 42, "foo" })
+	s.ctx.PushGlobalGoFunction("single", func() int { return 42 })
+	s.ctx.PushGlobalGoFunction("fail", func() error { return errors.New("bar") })
+
+	c.Assert(s.ctx.PevalString(`store([test(), single(), fail().error.message])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{
+		map[string]interface{}{"value0": 42.0, "value1": "foo"}, 42.0, "bar",
+	})
+}
+
+func (s *CandySuite) TestReturnError_Roundtrip(c *C) {
+	errSentinel := errors.New("foo")
+	s.ctx.PushGlobalGoFunction("fail", Func{
+		Fn:      func() (int, error) { return 0, errSentinel },
+		Returns: ReturnArray,
+	})
+	s.ctx.PushGlobalGoFunction("rethrow", func(err error) error { return err })
+
+	err := s.ctx.PevalString(`rethrow(fail()[1])`)
+	c.Assert(err, Equals, errSentinel)
+}
+
+func (s *CandySuite) TestReturnFunc(c *C) {
+	s.ctx.PushGlobalGoFunction("atoi", Func{Fn: strconv.Atoi, Returns: ReturnArray})
+	s.ctx.PushGlobalGoFunction("throwing", strconv.Atoi)
+
+	c.Assert(s.ctx.PevalString(`store(atoi("foo")[1].message)`), IsNil)
+	c.Assert(s.stored, Equals, `strconv.Atoi: parsing "foo": invalid syntax`)
+
+	c.Assert(s.ctx.PevalString(`throwing("foo")`), ErrorMatches, `strconv.Atoi: parsing "foo": invalid syntax`)
+	c.Assert(s.ctx.PevalString(`atoi()`), ErrorMatches, `atoi: expected 1 arguments, got 0`)
+}
+
+func (s *CandySuite) TestReturnUnsupported(c *C) {
+	s.ctx.PushGlobalGoFunction("split", Func{
+		Fn:      func() (int, complex128) { return 42, 1i },
+		Returns: ReturnArray,
+	})
+
+	err := s.ctx.PevalString(`var r = 0; try { split() } catch (e) { r = e.goType }; store(r)`)
+	c.Assert(err, IsNil)
+	c.Assert(s.stored, Equals, "*json.UnsupportedTypeError")
+	c.Assert(s.ctx.GoErrors(), HasLen, 1)
+	c.Assert(s.ctx.GoErrors()[0], ErrorMatches, "json: unsupported type: complex128")
+}
+
+func (s *CandySuite) TestResultNames(c *C) {
+	s.ctx.PushGlobalGoFunction("divmod", Func{
+		Fn:      func(a, b int) (int, int) { return a / b, a % b },
+		Results: []string{"q", "r"},
+	})
+
+	c.Assert(s.ctx.PevalString(`store(divmod(7, 2))`), IsNil)
+	c.Assert(s.stored, DeepEquals, map[string]interface{}{"q": 3.0, "r": 1.0})
+}
+
+func (s *CandySuite) TestResultNames_Context(c *C) {
+	s.ctx.SetResultNames((func(int, int) (int, int, error))(nil), "q", "r")
+	s.ctx.PushGlobalGoFunction("divmod", func(a, b int) (int, int, error) {
+		if b == 0 {
+			return 0, 0, errors.New("division by zero")
+		}
+
+		return a / b, a % b, nil
+	})
+
+	c.Assert(s.ctx.PevalString(`store(divmod(7, 2))`), IsNil)
+	c.Assert(s.stored, DeepEquals, map[string]interface{}{"q": 3.0, "r": 1.0})
+	c.Assert(s.ctx.PevalString(`divmod(7, 0)`), ErrorMatches, "division by zero")
+
+	s.ctx.SetReturnStyle(ReturnObject)
+	c.Assert(s.ctx.PevalString(`store(divmod(7, 0))`), IsNil)
+	c.Assert(s.stored.(map[string]interface{})["q"], Equals, 0.0)
+	c.Assert(s.stored.(map[string]interface{})["error"], NotNil)
+
+	s.ctx.SetReturnStyle(ReturnArray)
+	c.Assert(s.ctx.PevalString(`store(divmod(7, 2))`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{3.0, 1.0, nil})
+}
+
+func (s *CandySuite) TestResultNames_Callback(c *C) {
+	s.ctx.SetResultNames((func() (int, string))(nil), "n", "s")
+	s.ctx.PushGlobalGoFunction("test", func(fn func() (int, string)) string {
+		n, str := fn()
+		return strconv.Itoa(n) + str
+	})
+
+	c.Assert(s.ctx.PevalString(`store([
+		test(function() { return {n: 1, s: "a"} }),
+		test(function() { return [2, "b"] }),
+		test(function() { return {s: "c"} })
+	])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"1a", "2b", "0c"})
+}
+
+func (s *CandySuite) TestResultNames_CallbackStack(c *C) {
+	s.ctx.SetResultNames((func() (int, string))(nil), "n", "s")
+	var tops []int
+	s.ctx.PushGlobalGoFunction("test", func(named, array func() (int, string)) {
+		top := s.ctx.GetTop()
+		named()
+		array()
+		tops = append(tops, top, s.ctx.GetTop())
+	})
+
+	c.Assert(s.ctx.PevalString(`test(
+		function() { return {n: 1, s: "a"} },
+		function() { return [2, "b"] }
+	)`), IsNil)
+	c.Assert(tops[1], Equals, tops[0])
+}
+
+func (s *CandySuite) TestResultCount_Callback(c *C) {
+	s.ctx.PushGlobalGoFunction("test", func(fn func() (int, string, error)) string {
+		_, _, err := fn()
+		return err.Error()
+	})
+
+	c.Assert(s.ctx.PevalString(`store(test(function() { return [1] }))`), IsNil)
+	c.Assert(s.stored, Equals, "Invalid count of return value on proxied function. Expected 2 had 1")
+}
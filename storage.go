@@ -1,19 +1,37 @@
 package candyjs
 
+// #include <stdlib.h>
 import "C"
 import (
+	"reflect"
 	"sync"
 	"unsafe"
 )
 
 type storage struct {
-	vars map[unsafe.Pointer]interface{}
+	vars       map[unsafe.Pointer]interface{}
+	identities map[identity]proxyRef
 	sync.Mutex
 }
 
+// identity identifies a Go pointer, the type is part of the identity since a
+// struct and its first field share the same address.
+type identity struct {
+	t    reflect.Type
+	addr uintptr
+}
+
+// proxyRef is a weak reference to the JS proxy of a Go pointer, the heap
+// pointer is valid until the finalizer of the proxy target releases ptr.
+type proxyRef struct {
+	ptr     unsafe.Pointer
+	heapptr unsafe.Pointer
+}
+
 func newStorage() *storage {
 	return &storage{
-		vars: make(map[unsafe.Pointer]interface{}, 0),
+		vars:       make(map[unsafe.Pointer]interface{}, 0),
+		identities: make(map[identity]proxyRef, 0),
 	}
 }
 
@@ -27,9 +45,70 @@ func (s *storage) add(v interface{}) unsafe.Pointer {
 	return ptr
 }
 
+func (s *storage) remove(ptr unsafe.Pointer) {
+	s.Lock()
+	defer s.Unlock()
+
+	v, ok := s.vars[ptr]
+	if !ok {
+		return
+	}
+
+	if id, ok := identityOf(v); ok && s.identities[id].ptr == ptr {
+		delete(s.identities, id)
+	}
+
+	delete(s.vars, ptr)
+	C.free(ptr)
+}
+
+func (s *storage) len() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return len(s.vars)
+}
+
 func (s *storage) get(ptr unsafe.Pointer) interface{} {
 	s.Lock()
 	defer s.Unlock()
 
 	return s.vars[ptr]
 }
+
+// putProxy registers the heap pointer of the proxy pushed for v, only
+// pointers are registered.
+func (s *storage) putProxy(v interface{}, ptr, heapptr unsafe.Pointer) {
+	id, ok := identityOf(v)
+	if !ok {
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.identities[id] = proxyRef{ptr: ptr, heapptr: heapptr}
+}
+
+// getProxy returns the heap pointer of the proxy previously pushed for v or
+// nil.
+func (s *storage) getProxy(v interface{}) unsafe.Pointer {
+	id, ok := identityOf(v)
+	if !ok {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	return s.identities[id].heapptr
+}
+
+func identityOf(v interface{}) (identity, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return identity{}, false
+	}
+
+	return identity{t: rv.Type(), addr: rv.Pointer()}, true
+}
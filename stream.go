@@ -0,0 +1,405 @@
+package candyjs
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultChunkSize = 32 * 1024
+
+var (
+	readerKeys = []string{"read", "readBytes", "readAll", "readLine", "forEach", "close"}
+	writerKeys = []string{"write", "close"}
+)
+
+// streamProxy is the Proxy of the values implementing io.Reader or io.Writer,
+// the readers expose:
+//   - read(n): reads up to n bytes, returns null at EOF. A character split by
+//     n is completed, so the chunk can be up to 3 bytes longer
+//   - readBytes(n): like read, the bytes are returned as an Uint8Array
+//   - readAll(): reads until EOF
+//   - readLine(): reads a line without the line ending, returns null at EOF
+//   - forEach(fn, size): calls fn with the chunks of read(size) until EOF or
+//     fn returns false
+//
+// the writers expose write(v), taking a string or an Uint8Array, and both
+// expose close(). Any other key is resolved by the fallback proxy.
+//
+// The reader is never read ahead, so it can be shared with Go.
+type streamProxy struct {
+	Fallback Proxy
+	r        io.Reader
+	// err is the error of a read returning bytes, returned by the next read.
+	err error
+}
+
+func newStreamProxy(v interface{}, fallback Proxy) *streamProxy {
+	p := &streamProxy{Fallback: fallback}
+	if r, ok := v.(io.Reader); ok {
+		p.r = r
+	}
+
+	return p
+}
+
+func (p *streamProxy) Has(t interface{}, k string) bool {
+	if p.isStreamKey(t, k) {
+		return true
+	}
+
+	return p.Fallback.Has(t, k)
+}
+
+func (p *streamProxy) Get(t interface{}, k string, recv interface{}) (interface{}, error) {
+	if !p.isStreamKey(t, k) {
+		return p.Fallback.Get(t, k, recv)
+	}
+
+	switch k {
+	case "read":
+		return p.read, nil
+	case "readBytes":
+		return p.readBytes, nil
+	case "readAll":
+		return p.readAll, nil
+	case "readLine":
+		return p.readLine, nil
+	case "forEach":
+		return p.forEach, nil
+	case "write":
+		return func(v interface{}) (int, error) { return write(t.(io.Writer), v) }, nil
+	case "close":
+		return func() error { return closeStream(t) }, nil
+	}
+
+	return nil, nil
+}
+
+func (p *streamProxy) Set(t interface{}, k string, v, recv interface{}) (bool, error) {
+	if p.isStreamKey(t, k) {
+		return false, nil
+	}
+
+	return p.Fallback.Set(t, k, v, recv)
+}
+
+func (p *streamProxy) Enumerate(t interface{}) (interface{}, error) {
+	keys := p.keys(t)
+	names, err := p.Fallback.Enumerate(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if names, ok := names.([]string); ok {
+		for _, name := range names {
+			if !p.isStreamKey(t, name) {
+				keys = append(keys, name)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+func (p *streamProxy) isMethod(t interface{}, k string) bool {
+	if p.isStreamKey(t, k) {
+		return false
+	}
+
+	mp, ok := p.Fallback.(methodProxy)
+	return ok && mp.isMethod(t, k)
+}
+
+func (p *streamProxy) isStreamKey(t interface{}, k string) bool {
+	for _, key := range p.keys(t) {
+		if key == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *streamProxy) keys(t interface{}) []string {
+	var keys []string
+	if p.r != nil {
+		keys = append(keys, readerKeys...)
+	}
+
+	if _, ok := t.(io.Writer); ok {
+		for _, key := range writerKeys {
+			if p.r == nil || key != "close" {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys
+}
+
+func (p *streamProxy) read(n int) (interface{}, error) {
+	b, err := p.readChunk(n)
+	if b == nil {
+		return nil, err
+	}
+
+	return string(p.completeRune(b)), nil
+}
+
+// completeRune reads the missing bytes of the character split at the end of
+// b, if any, one byte at a time to not read past it.
+func (p *streamProxy) completeRune(b []byte) []byte {
+	for p.err == nil && isSplitRune(b) {
+		c, err := p.readByte()
+		if err != nil {
+			if err != io.EOF {
+				p.err = err
+			}
+
+			break
+		}
+
+		b = append(b, c)
+	}
+
+	return b
+}
+
+// isSplitRune returns true if b ends with the first bytes of a character.
+func isSplitRune(b []byte) bool {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			return !utf8.FullRune(b[i:])
+		}
+	}
+
+	return false
+}
+
+func (p *streamProxy) readBytes(n int) (interface{}, error) {
+	b, err := p.readChunk(n)
+	if b == nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// readChunk reads up to n bytes, it returns nil at EOF. The error of a read
+// returning bytes is returned by the next read.
+func (p *streamProxy) readChunk(n int) ([]byte, error) {
+	if err := p.takeErr(); err != nil {
+		return nil, err
+	}
+
+	if n <= 0 {
+		n = defaultChunkSize
+	}
+
+	b := make([]byte, n)
+	n, err := p.r.Read(b)
+	if err != nil && err != io.EOF {
+		if n == 0 {
+			return nil, err
+		}
+
+		p.err = err
+	}
+
+	if err == io.EOF && n == 0 {
+		return nil, nil
+	}
+
+	return b[:n], nil
+}
+
+// takeErr returns and clears the error held back by the last read.
+func (p *streamProxy) takeErr() error {
+	err := p.err
+	p.err = nil
+	return err
+}
+
+func (p *streamProxy) readAll() (string, error) {
+	if err := p.takeErr(); err != nil {
+		return "", err
+	}
+
+	b, err := ioutil.ReadAll(p.r)
+	return string(b), err
+}
+
+func (p *streamProxy) readLine() (interface{}, error) {
+	var line []byte
+	for {
+		c, err := p.readByte()
+		if err == io.EOF {
+			if len(line) == 0 {
+				return nil, nil
+			}
+
+			break
+		}
+
+		if err != nil {
+			if len(line) == 0 {
+				return nil, err
+			}
+
+			p.err = err
+			break
+		}
+
+		if c == '\n' {
+			break
+		}
+
+		line = append(line, c)
+	}
+
+	return strings.TrimSuffix(string(line), "\r"), nil
+}
+
+// readByte reads a single byte, the lines are read byte by byte to not read
+// past the line ending.
+func (p *streamProxy) readByte() (byte, error) {
+	if err := p.takeErr(); err != nil {
+		return 0, err
+	}
+
+	if br, ok := p.r.(io.ByteReader); ok {
+		return br.ReadByte()
+	}
+
+	var b [1]byte
+	for {
+		n, err := p.r.Read(b[:])
+		if n == 1 {
+			return b[0], nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (p *streamProxy) forEach(fn func(chunk string) interface{}, size int) error {
+	for {
+		chunk, err := p.read(size)
+		if err != nil || chunk == nil {
+			return err
+		}
+
+		if r, isBool := fn(chunk.(string)).(bool); isBool && !r {
+			return nil
+		}
+	}
+}
+
+func write(w io.Writer, v interface{}) (int, error) {
+	switch b := v.(type) {
+	case string:
+		return io.WriteString(w, b)
+	case []byte:
+		return w.Write(b)
+	}
+
+	return 0, errorf(ErrorCodeTypeError, "Cannot write value of type %T", v)
+}
+
+func closeStream(v interface{}) error {
+	if c, ok := v.(io.Closer); ok {
+		return c.Close()
+	}
+
+	return nil
+}
+
+var typeJSWriter = reflect.TypeOf(&jsWriter{})
+
+// jsWriter is an io.Writer backed by a JS object with a `writeBytes` or a
+// `write` method, the chunks are passed to writeBytes as an Uint8Array or else
+// to write as a string. The JS object is pinned, so the writer can be kept
+// once the Go function receiving it returns.
+type jsWriter struct {
+	ctx *Context
+	obj *jsRef
+}
+
+// isJSWriter returns true if the value at the given index can be passed as a
+// Go value of type t using a jsWriter.
+func (ctx *Context) isJSWriter(index int, t reflect.Type) bool {
+	if t.Kind() != reflect.Interface || t.NumMethod() == 0 || !typeJSWriter.Implements(t) {
+		return false
+	}
+
+	if !ctx.IsObject(index) || ctx.IsFunction(index) {
+		return false
+	}
+
+	return ctx.hasMethod(index, "writeBytes") || ctx.hasMethod(index, "write")
+}
+
+// hasMethod returns true if the object at the given index has a method with
+// the given name.
+func (ctx *Context) hasMethod(index int, name string) bool {
+	ctx.GetPropString(index, name)
+	defer ctx.Pop()
+
+	return ctx.IsFunction(-1)
+}
+
+func (w *jsWriter) Write(p []byte) (int, error) {
+	var err error
+	if w.hasMethod("writeBytes") {
+		err = w.call("writeBytes", p)
+	} else {
+		err = w.call("write", string(p))
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close calls the `close` method of the JS object, if any.
+func (w *jsWriter) Close() error {
+	if !w.hasMethod("close") {
+		return nil
+	}
+
+	return w.call("close")
+}
+
+func (w *jsWriter) hasMethod(name string) bool {
+	w.obj.push()
+	defer w.ctx.Pop()
+
+	return w.ctx.hasMethod(-1, name)
+}
+
+func (w *jsWriter) call(method string, args ...interface{}) error {
+	w.obj.push()
+	obj := w.ctx.NormalizeIndex(-1)
+	w.ctx.PushString(method)
+	for _, arg := range args {
+		if err := w.ctx.PushInterface(arg); err != nil {
+			w.ctx.PushUndefined()
+		}
+	}
+
+	defer w.ctx.Pop2()
+	var rc int
+	w.ctx.enterJS(func() { rc = w.ctx.PcallProp(obj, len(args)) })
+	if rc != 0 {
+		return w.ctx.getError(-1)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package candyjs
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestStreamReaderRead(c *C) {
+	s.ctx.PushGlobalProxy("r", strings.NewReader("foobar"))
+	c.Assert(s.ctx.PevalString(`store([r.read(3), r.read(10), r.read(3)])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"foo", "bar", nil})
+}
+
+func (s *CandySuite) TestStreamReaderReadAll(c *C) {
+	s.ctx.PushGlobalGoFunction("open", func() io.ReadCloser {
+		return io.NopCloser(strings.NewReader("foo\nbar"))
+	})
+
+	c.Assert(s.ctx.PevalString(`var r = open(); store(r.readAll()); r.close()`), IsNil)
+	c.Assert(s.stored, Equals, "foo\nbar")
+}
+
+func (s *CandySuite) TestStreamReaderReadLine(c *C) {
+	s.ctx.PushGlobalProxy("r", strings.NewReader("foo\r\nbar\nqux"))
+	c.Assert(s.ctx.PevalString(`
+		var lines = [], line;
+		while ((line = r.readLine()) !== null) lines.push(line);
+		store(lines)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"foo", "bar", "qux"})
+}
+
+func (s *CandySuite) TestStreamReaderForEach(c *C) {
+	s.ctx.PushGlobalProxy("r", strings.NewReader("foobarqux"))
+	c.Assert(s.ctx.PevalString(`
+		var chunks = [];
+		r.forEach(function(chunk) { chunks.push(chunk); return chunks.length < 2 }, 3);
+		store(chunks)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"foo", "bar"})
+}
+
+func (s *CandySuite) TestStreamReaderMultibyte(c *C) {
+	s.ctx.PushGlobalProxy("r", strings.NewReader("héllo wörld"))
+	c.Assert(s.ctx.PevalString(`store([r.read(2), r.read(2), r.read(2)])`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"hé", "ll", "o "})
+
+	c.Assert(s.ctx.PevalString(`
+		var chunks = [];
+		r.forEach(function(chunk) { chunks.push(chunk) }, 1);
+		store(chunks)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"w", "ö", "r", "l", "d"})
+}
+
+func (s *CandySuite) TestStreamWriter(c *C) {
+	buf := bytes.NewBuffer(nil)
+	s.ctx.PushGlobalProxy("w", buf)
+
+	c.Assert(s.ctx.PevalString(`w.write("foo"); w.write("bar"); store(w.len())`), IsNil)
+	c.Assert(s.stored, Equals, 6.0)
+	c.Assert(buf.String(), Equals, "foobar")
+}
+
+func (s *CandySuite) TestStreamWriterType(c *C) {
+	s.ctx.PushGlobalProxy("w", bytes.NewBuffer(nil))
+
+	err := s.ctx.PevalString(`w.write(42)`)
+	c.Assert(err, ErrorMatches, "Cannot write value of type float64")
+	c.Assert(ErrorCode(err), Equals, ErrorCodeTypeError)
+}
+
+// dataErrReader returns its data along with err, then err.
+type dataErrReader struct {
+	data string
+	err  error
+}
+
+func (r *dataErrReader) Read(b []byte) (int, error) {
+	n := copy(b, r.data)
+	r.data = r.data[n:]
+	return n, r.err
+}
+
+func (s *CandySuite) TestStreamReaderDataError(c *C) {
+	s.ctx.PushGlobalProxy("r", &dataErrReader{data: "foo", err: errGoSentinel})
+
+	c.Assert(s.ctx.PevalString(`store(r.read(10))`), IsNil)
+	c.Assert(s.stored, Equals, "foo")
+	c.Assert(s.ctx.PevalString(`r.read(10)`), Equals, errGoSentinel)
+}
+
+func (s *CandySuite) TestStreamJSWriter(c *C) {
+	s.ctx.PushGlobalGoFunction("greet", func(w io.WriteCloser, name string) error {
+		if _, err := io.WriteString(w, "hello "+name); err != nil {
+			return err
+		}
+
+		return w.Close()
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		var out = [];
+		greet({
+			write: function(s) { out.push(s) },
+			close: function() { out.push("closed") }
+		}, "foo");
+		store(out)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{"hello foo", "closed"})
+}
+
+func (s *CandySuite) TestStreamReaderShared(c *C) {
+	for _, r := range []io.Reader{
+		strings.NewReader("foo\nbar\nqux"),
+		io.LimitReader(strings.NewReader("foo\nbar\nqux"), 100),
+	} {
+		s.ctx.PushGlobalProxy("r", r)
+		s.ctx.PushGlobalGoFunction("readRest", func(r io.Reader) (string, error) {
+			b, err := ioutil.ReadAll(r)
+			return string(b), err
+		})
+
+		c.Assert(s.ctx.PevalString(`store([r.readLine(), r.read(2), readRest(r)])`), IsNil)
+		c.Assert(s.stored, DeepEquals, []interface{}{"foo", "ba", "r\nqux"})
+	}
+}
+
+func (s *CandySuite) TestStreamBytes(c *C) {
+	binary := []byte{0xff, 0x00, 0xed, 0xa0, 0x80}
+	buf := bytes.NewBuffer(nil)
+	s.ctx.PushGlobalProxy("r", bytes.NewReader(binary))
+	s.ctx.PushGlobalProxy("w", buf)
+
+	c.Assert(s.ctx.PevalString(`
+		var b = r.readBytes(10);
+		w.write(b);
+		store([b instanceof Uint8Array, b.length, r.readBytes(10)])
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, []interface{}{true, 5.0, nil})
+	c.Assert(buf.Bytes(), DeepEquals, binary)
+}
+
+func (s *CandySuite) TestStreamJSWriterBytes(c *C) {
+	binary := []byte{0xff, 0x00, 0xed, 0xa0, 0x80}
+	s.ctx.PushGlobalGoFunction("dump", func(w io.Writer) error {
+		_, err := w.Write(binary)
+		return err
+	})
+
+	c.Assert(s.ctx.PevalString(`
+		var out;
+		dump({
+			write: function(s) { throw new Error("unexpected") },
+			writeBytes: function(b) { out = b }
+		});
+		store(out)
+	`), IsNil)
+	c.Assert(s.stored, DeepEquals, binary)
+}
@@ -0,0 +1,211 @@
+package candyjs
+
+import (
+	"math"
+	"reflect"
+	"time"
+)
+
+var typeDuration = reflect.TypeOf(time.Duration(0))
+
+// SetTimeLocation sets the location of the time.Time values decoded from JS,
+// by default the times are decoded in UTC.
+func (ctx *Context) SetTimeLocation(loc *time.Location) {
+	ctx.location = loc
+}
+
+// pushTime pushes t as a `Date`.
+func (ctx *Context) pushTime(t time.Time) {
+	ctx.GetGlobalString("Date")
+	ctx.PushNumber(float64(t.Unix()*1000 + int64(t.Nanosecond()/int(time.Millisecond))))
+	ctx.New(1)
+}
+
+// isTimeType returns true if t is time.Time or a type convertible to it.
+func isTimeType(t reflect.Type) bool {
+	return t == typeTime || (t.Kind() == reflect.Struct && t.ConvertibleTo(typeTime))
+}
+
+// isTimePtrType returns true if t is a pointer to a time or a time.Duration.
+func isTimePtrType(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && (t.Elem() == typeDuration || isTimeType(t.Elem()))
+}
+
+// getTimeValue decodes the value at the given index into a time.Time or a
+// time.Duration, only for the targets of these types: the `Date` instances
+// decoded into interface{} remain the JSON strings. The numbers are
+// milliseconds, since the epoch for times, and the strings are parsed as RFC
+// 3339 times or Go durations. The pointers to these types are also decoded.
+// ok is false if t is not one of these types or the value is not a date, a
+// number or a string, err is the error of the values decoded into an invalid
+// time.
+func (ctx *Context) getTimeValue(index int, t reflect.Type) (v reflect.Value, ok bool, err error) {
+	if t == nil {
+		return reflect.Value{}, false, nil
+	}
+
+	if isTimePtrType(t) {
+		v, ok, err = ctx.getTimeValue(index, t.Elem())
+		if !ok || err != nil {
+			return v, ok, err
+		}
+
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(v)
+		return ptr, true, nil
+	}
+
+	switch {
+	case t == typeDuration:
+		var d time.Duration
+		switch {
+		case ctx.IsNumber(index):
+			d, err = toDuration(ctx.GetNumber(index))
+		case ctx.IsString(index):
+			d, err = toDuration(ctx.GetString(index))
+		default:
+			return reflect.Value{}, false, nil
+		}
+
+		return reflect.ValueOf(d), true, err
+	case isTimeType(t):
+		var tm time.Time
+		switch {
+		case ctx.isDate(index):
+			tm, err = toTime(ctx.getDateMillis(index))
+		case ctx.IsNumber(index):
+			tm, err = toTime(ctx.GetNumber(index))
+		case ctx.IsString(index):
+			tm, err = toTime(ctx.GetString(index))
+		default:
+			return reflect.Value{}, false, nil
+		}
+
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+
+		if ctx.location != nil {
+			tm = tm.In(ctx.location)
+		}
+
+		return reflect.ValueOf(tm).Convert(t), true, nil
+	}
+
+	return reflect.Value{}, false, nil
+}
+
+func (ctx *Context) isDate(index int) bool {
+	if !ctx.IsObject(index) {
+		return false
+	}
+
+	index = ctx.NormalizeIndex(index)
+	ctx.GetGlobalString("Date")
+	defer ctx.Pop()
+
+	return ctx.Instanceof(index, -1)
+}
+
+func (ctx *Context) getDateMillis(index int) float64 {
+	index = ctx.NormalizeIndex(index)
+	ctx.PushString("getTime")
+	defer ctx.Pop()
+
+	if ctx.PcallProp(index, 0) != 0 {
+		return math.NaN()
+	}
+
+	return ctx.GetNumber(-1)
+}
+
+// toTime converts milliseconds since the epoch, RFC 3339 strings and times
+// into a time.Time in UTC or, for the strings, in their own offset.
+func toTime(v interface{}) (time.Time, error) {
+	switch tv := v.(type) {
+	case time.Time:
+		return tv, nil
+	case float64:
+		if math.IsNaN(tv) || math.IsInf(tv, 0) {
+			return time.Time{}, errorf(ErrorCodeInvalidTime, "Invalid time %v", tv)
+		}
+
+		ms := int64(tv)
+		ns := int64(math.Round((tv - float64(ms)) * float64(time.Millisecond)))
+		return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond)+ns).UTC(), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, tv)
+		if err != nil {
+			return time.Time{}, errorf(ErrorCodeInvalidTime, "Invalid time %q", tv)
+		}
+
+		return t, nil
+	}
+
+	return time.Time{}, errorf(ErrorCodeInvalidTime, "Invalid time %v", v)
+}
+
+// toDuration converts milliseconds and strings like "1h30m" into a
+// time.Duration, the milliseconds must be finite and in the range of
+// time.Duration.
+func toDuration(v interface{}) (time.Duration, error) {
+	switch dv := v.(type) {
+	case float64:
+		ns := dv * float64(time.Millisecond)
+		if math.IsNaN(ns) || ns >= math.MaxInt64 || ns < math.MinInt64 {
+			return 0, errorf(ErrorCodeInvalidTime, "Invalid duration %v", dv)
+		}
+
+		return time.Duration(ns), nil
+	case string:
+		d, err := time.ParseDuration(dv)
+		if err != nil {
+			return 0, errorf(ErrorCodeInvalidTime, "Invalid duration %q", dv)
+		}
+
+		return d, nil
+	}
+
+	return 0, errorf(ErrorCodeInvalidTime, "Invalid duration %v", v)
+}
+
+// convertTime like Convert for the time.Time and time.Duration types, and
+// the pointers to them, ok is false if t is not one of these types or the value is not a number, a string
+// or, for the times, a time.Time.
+func convertTime(t reflect.Type, value interface{}) (v interface{}, ok bool, err error) {
+	if isTimePtrType(t) {
+		v, ok, err = convertTime(t.Elem(), value)
+		if !ok || err != nil {
+			return v, ok, err
+		}
+
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(reflect.ValueOf(v))
+		return ptr.Interface(), true, nil
+	}
+
+	switch value.(type) {
+	case float64, string:
+	case time.Time:
+		if !isTimeType(t) {
+			return nil, false, nil
+		}
+	default:
+		return nil, false, nil
+	}
+
+	switch {
+	case t == typeDuration:
+		d, err := toDuration(value)
+		return d, true, err
+	case isTimeType(t):
+		tm, err := toTime(value)
+		if err != nil {
+			return nil, true, err
+		}
+
+		return reflect.ValueOf(tm).Convert(t).Interface(), true, nil
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,119 @@
+package candyjs
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestTimeFromDate(c *C) {
+	var t time.Time
+	s.ctx.PushGlobalGoFunction("receive", func(v time.Time) { t = v })
+
+	c.Assert(s.ctx.PevalString(`receive(new Date(Date.UTC(2019, 5, 17, 9, 3, 34, 141)))`), IsNil)
+	c.Assert(t, Equals, time.Date(2019, 6, 17, 9, 3, 34, 141*int(time.Millisecond), time.UTC))
+}
+
+func (s *CandySuite) TestTimeFromNumberAndString(c *C) {
+	var t time.Time
+	s.ctx.PushGlobalGoFunction("receive", func(v time.Time) { t = v })
+
+	c.Assert(s.ctx.PevalString(`receive(1560762214141)`), IsNil)
+	c.Assert(t, Equals, time.Date(2019, 6, 17, 9, 3, 34, 141*int(time.Millisecond), time.UTC))
+
+	c.Assert(s.ctx.PevalString(`receive("2019-06-17T11:03:34.141000001+02:00")`), IsNil)
+	c.Assert(t.Equal(time.Date(2019, 6, 17, 9, 3, 34, 141000001, time.UTC)), Equals, true)
+}
+
+func (s *CandySuite) TestTimeInterface(c *C) {
+	c.Assert(s.ctx.PevalString(`store(new Date(Date.UTC(2019, 5, 17)))`), IsNil)
+	c.Assert(s.stored, Equals, "2019-06-17T00:00:00.000Z")
+
+	var t *time.Time
+	s.ctx.PushGlobalGoFunction("receive", func(v *time.Time) { t = v })
+
+	c.Assert(s.ctx.PevalString(`receive(new Date(Date.UTC(2019, 5, 17)))`), IsNil)
+	c.Assert(*t, Equals, time.Date(2019, 6, 17, 0, 0, 0, 0, time.UTC))
+}
+
+func (s *CandySuite) TestTimeLocation(c *C) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	s.ctx.SetTimeLocation(loc)
+
+	var t time.Time
+	s.ctx.PushGlobalGoFunction("receive", func(v time.Time) { t = v })
+
+	c.Assert(s.ctx.PevalString(`receive(new Date(Date.UTC(2019, 5, 17)))`), IsNil)
+	c.Assert(t.Location(), Equals, loc)
+	c.Assert(t.Hour(), Equals, 2)
+}
+
+func (s *CandySuite) TestTimeStructField(c *C) {
+	m := &MyStruct{}
+	s.ctx.PushGlobalProxy("m", m)
+
+	c.Assert(s.ctx.PevalString(`m.date = 1560762214141`), IsNil)
+	c.Assert(m.Date, Equals, time.Date(2019, 6, 17, 9, 3, 34, 141*int(time.Millisecond), time.UTC))
+
+	c.Assert(s.ctx.PevalString(`m.date = new Date(0)`), IsNil)
+	c.Assert(m.Date, Equals, time.Unix(0, 0).UTC())
+}
+
+func (s *CandySuite) TestDuration(c *C) {
+	var d time.Duration
+	s.ctx.PushGlobalGoFunction("receive", func(v time.Duration) { d = v })
+
+	c.Assert(s.ctx.PevalString(`receive(1500)`), IsNil)
+	c.Assert(d, Equals, 1500*time.Millisecond)
+
+	c.Assert(s.ctx.PevalString(`receive("1h30m")`), IsNil)
+	c.Assert(d, Equals, 90*time.Minute)
+}
+
+func (s *CandySuite) TestDurationGetValue(c *C) {
+	var d time.Duration
+	c.Assert(s.ctx.PevalString(`"2s"`), IsNil)
+	c.Assert(s.ctx.GetValue(-1, &d), IsNil)
+	c.Assert(d, Equals, 2*time.Second)
+}
+
+func (s *CandySuite) TestDurationInvalid(c *C) {
+	s.ctx.PushGlobalGoFunction("receive", func(v time.Duration) {})
+
+	for _, src := range []string{`receive(NaN)`, `receive(Infinity)`, `receive(-1e300)`, `receive(1e300)`} {
+		err := s.ctx.PevalString(src)
+		c.Assert(err, ErrorMatches, `receive: argument 1 Invalid duration .*`, Commentf(src))
+		c.Assert(ErrorCode(err), Equals, ErrorCodeInvalidTime)
+	}
+
+	var d time.Duration
+	c.Assert(s.ctx.PevalString(`1e300`), IsNil)
+	err := s.ctx.GetValue(-1, &d)
+	c.Assert(err, ErrorMatches, `Invalid duration 1e\+300`)
+	c.Assert(ErrorCode(err), Equals, ErrorCodeInvalidTime)
+}
+
+func (s *CandySuite) TestTimePointer(c *C) {
+	var t *time.Time
+	var d *time.Duration
+	s.ctx.PushGlobalGoFunction("ptime", func(v *time.Time) { t = v })
+	s.ctx.PushGlobalGoFunction("pdur", func(v *time.Duration) { d = v })
+
+	c.Assert(s.ctx.PevalString(`ptime(1560762214141)`), IsNil)
+	c.Assert(*t, Equals, time.Date(2019, 6, 17, 9, 3, 34, 141*int(time.Millisecond), time.UTC))
+
+	c.Assert(s.ctx.PevalString(`ptime(null)`), IsNil)
+	c.Assert(t, IsNil)
+
+	c.Assert(s.ctx.PevalString(`pdur("2s")`), IsNil)
+	c.Assert(*d, Equals, 2*time.Second)
+
+	err := s.ctx.PevalString(`ptime("foo")`)
+	c.Assert(err, ErrorMatches, `ptime: argument 1 Invalid time "foo"`)
+	c.Assert(ErrorCode(err), Equals, ErrorCodeInvalidTime)
+
+	m := &struct{ Date *time.Time }{}
+	s.ctx.PushGlobalProxy("m", m)
+	c.Assert(s.ctx.PevalString(`m.date = 1560762214141`), IsNil)
+	c.Assert(*m.Date, Equals, time.Date(2019, 6, 17, 9, 3, 34, 141*int(time.Millisecond), time.UTC))
+}
@@ -0,0 +1,102 @@
+package candyjs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typeCache holds the *typeInfo of every type handled by the ReflectProxy.
+var typeCache sync.Map // map[reflect.Type]*typeInfo
+
+// typeInfo caches the reflection metadata of a type: the resolved JS keys and
+// the enumerable names. It is safe for concurrent use.
+type typeInfo struct {
+	t       reflect.Type
+	members sync.Map // map[string]member
+
+	namesOnce sync.Once
+	names     []string
+}
+
+// member is a JS key resolved against a type, field is nil if the key is not
+// a field and method is -1 if the key is not a method.
+type member struct {
+	field  []int
+	method int
+}
+
+func getTypeInfo(t reflect.Type) *typeInfo {
+	if info, ok := typeCache.Load(t); ok {
+		return info.(*typeInfo)
+	}
+
+	info, _ := typeCache.LoadOrStore(t, newTypeInfo(t))
+	return info.(*typeInfo)
+}
+
+func newTypeInfo(t reflect.Type) *typeInfo {
+	return &typeInfo{t: t}
+}
+
+// member resolves the given key, only the declared fields and methods are
+// cached: the keys come from the scripts, caching the misses would grow the
+// cache without bound, and they are map keys for the map types.
+func (i *typeInfo) member(key string) member {
+	if m, ok := i.members.Load(key); ok {
+		return m.(member)
+	}
+
+	m := i.resolve(key)
+	if m.field != nil || m.method != -1 {
+		i.members.Store(key, m)
+	}
+
+	return m
+}
+
+func (i *typeInfo) resolve(key string) member {
+	m := member{method: -1}
+	if i.t.Kind() == reflect.Struct {
+		if name := nameToFieldName(i.t, key); name != "" {
+			if f, ok := i.t.FieldByName(name); ok {
+				m.field = f.Index
+			}
+		}
+	}
+
+	for _, name := range nameToGo(key) {
+		if method, ok := i.t.MethodByName(name); ok {
+			m.method = method.Index
+			break
+		}
+	}
+
+	return m
+}
+
+// propertyNames returns the names of the fields and methods, the returned
+// slice must not be modified.
+func (i *typeInfo) propertyNames() []string {
+	i.namesOnce.Do(func() {
+		switch i.t.Kind() {
+		case reflect.Ptr:
+			i.names = append(i.names, getTypeInfo(i.t.Elem()).propertyNames()...)
+		case reflect.Struct:
+			visitFields(i.t, func(f reflect.StructField) bool {
+				i.names = append(i.names, fieldToName(f))
+				return false
+			})
+		}
+
+		for j := 0; j < i.t.NumMethod(); j++ {
+			methodName := i.t.Method(j).Name
+			if !isExported(methodName) {
+				continue
+			}
+
+			i.names = append(i.names, nameToJavaScript(methodName))
+		}
+	})
+
+	return i.names
+}
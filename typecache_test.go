@@ -0,0 +1,182 @@
+package candyjs
+
+import (
+	"reflect"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *CandySuite) TestTypeInfo_Member(c *C) {
+	info := getTypeInfo(reflect.TypeOf(MyStruct{}))
+	c.Assert(info, Equals, getTypeInfo(reflect.TypeOf(MyStruct{})))
+
+	m := info.member("int")
+	c.Assert(m.field, DeepEquals, []int{1})
+	c.Assert(m.method, Equals, -1)
+
+	m = info.member("foo")
+	c.Assert(m.field, IsNil)
+	c.Assert(m.method, Equals, -1)
+
+	m = getTypeInfo(reflect.TypeOf(&MyStruct{})).member("multiply")
+	c.Assert(m.field, IsNil)
+	c.Assert(m.method, Equals, 0)
+}
+
+func (s *CandySuite) TestTypeInfo_MemberMissesNotCached(c *C) {
+	for _, t := range []reflect.Type{reflect.TypeOf(customMap{}), reflect.TypeOf(&MyStruct{})} {
+		info := newTypeInfo(t)
+		info.member("foo")
+		info.member("functionWithoutPtr")
+		info.member("multiply")
+
+		var keys []interface{}
+		info.members.Range(func(k, v interface{}) bool {
+			keys = append(keys, k)
+			return true
+		})
+		c.Assert(len(keys) <= 1, Equals, true)
+		c.Assert(info.member("foo").method, Equals, -1)
+	}
+}
+
+func (s *CandySuite) TestTypeInfo_MemberScriptKeys(c *C) {
+	info := getTypeInfo(reflect.TypeOf(&MyStruct{}))
+	s.ctx.PushGlobalProxy("test", &MyStruct{})
+
+	c.Assert(s.ctx.PevalString(`
+		for (var i = 0; i < 1000; i++) {
+			("k" + i) in test;
+		}
+	`), IsNil)
+
+	var count int
+	info.members.Range(func(k, v interface{}) bool {
+		count++
+		return true
+	})
+	c.Assert(count < 10, Equals, true)
+}
+
+func (s *CandySuite) TestTypeInfo_PropertyNames(c *C) {
+	names := getTypeInfo(reflect.TypeOf(&MyNestedStruct{})).propertyNames()
+	c.Assert(names, DeepEquals, []string{"name", "sayHello"})
+}
+
+func BenchmarkReflectProxy_Get(b *testing.B) {
+	t := &MyStruct{Int: 42}
+	for i := 0; i < b.N; i++ {
+		p.Get(t, "int", nil)
+		p.Get(t, "multiply", nil)
+	}
+}
+
+// BenchmarkReflectProxy_GetBaseline resolves the keys like ReflectProxy.Get
+// did before the type cache, see baselineProperty.
+func BenchmarkReflectProxy_GetBaseline(b *testing.B) {
+	v := reflect.ValueOf(&MyStruct{Int: 42})
+	for i := 0; i < b.N; i++ {
+		baselineProperty("int", v)
+		baselineProperty("multiply", v)
+	}
+}
+
+func BenchmarkReflectProxy_Enumerate(b *testing.B) {
+	t := &MyStruct{Int: 42}
+	for i := 0; i < b.N; i++ {
+		p.Enumerate(t)
+	}
+}
+
+// BenchmarkReflectProxy_EnumerateBaseline lists the names like
+// ReflectProxy.Enumerate did before the type cache, see baselineNames.
+func BenchmarkReflectProxy_EnumerateBaseline(b *testing.B) {
+	v := reflect.ValueOf(&MyStruct{Int: 42})
+	for i := 0; i < b.N; i++ {
+		baselineNames(v)
+	}
+}
+
+func BenchmarkPushProxy_Loop(b *testing.B) {
+	ctx := NewContext()
+	defer ctx.DestroyHeap()
+
+	records := make([]*MyStruct, 100)
+	for i := range records {
+		records[i] = &MyStruct{Int: i}
+	}
+	ctx.PushGlobalInterface("records", records)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := ctx.PevalString(`
+			var sum = 0;
+			for (var i = 0; i < records.length; i++) {
+				sum += records[i].int + records[i].multiply(2);
+			}
+		`)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ctx.Pop()
+	}
+}
+
+// baselineProperty is the lookup of the properties before the type cache.
+func baselineProperty(key string, v reflect.Value) (reflect.Value, bool) {
+	var r reflect.Value
+	switch v.Kind() {
+	case reflect.Ptr:
+		if r, ok := baselineMethod(key, v); ok {
+			return r, ok
+		}
+
+		return baselineProperty(key, v.Elem())
+	case reflect.Struct:
+		if name := nameToFieldName(v.Type(), key); name != "" {
+			r = v.FieldByName(name)
+		}
+	case reflect.Map:
+		r = v.MapIndex(reflect.ValueOf(key))
+	}
+
+	if !r.IsValid() {
+		return baselineMethod(key, v)
+	}
+
+	return r, true
+}
+
+func baselineMethod(key string, v reflect.Value) (reflect.Value, bool) {
+	var r reflect.Value
+	for _, name := range nameToGo(key) {
+		if r = v.MethodByName(name); r.IsValid() {
+			break
+		}
+	}
+
+	return r, r.IsValid()
+}
+
+// baselineNames is the listing of the names before the type cache.
+func baselineNames(v reflect.Value) []string {
+	var names []string
+	switch v.Kind() {
+	case reflect.Ptr:
+		names = baselineNames(v.Elem())
+	case reflect.Struct:
+		visitFields(v.Type(), func(f reflect.StructField) bool {
+			names = append(names, fieldToName(f))
+			return false
+		})
+	}
+
+	for i := 0; i < v.NumMethod(); i++ {
+		if name := v.Type().Method(i).Name; isExported(name) {
+			names = append(names, nameToJavaScript(name))
+		}
+	}
+
+	return names
+}